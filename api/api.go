@@ -34,6 +34,10 @@ func NsSysDataPull() []byte {
 	return []byte("hp:sys:config:ext_data_pull")
 }
 
+func NsSysPreviewKey() []byte {
+	return []byte("hp:sys:config:preview_key")
+}
+
 func NsSysNodeSearch(bukname string) []byte {
 	return []byte("hp:sys:config:ext_node_search:" + bukname)
 }
@@ -42,6 +46,54 @@ func NsTextSearchCacheNodeEntry(bukname, id string) []byte {
 	return []byte("hp:cache:node:" + bukname + ":" + id)
 }
 
+func NsTextSearchLocalDocument(bukname, id string) []byte {
+	return []byte("hp:search:doc:" + bukname + ":" + id)
+}
+
+func NsTextSearchLocalTerm(bukname, term, id string) []byte {
+	return []byte("hp:search:term:" + bukname + ":" + term + ":" + id)
+}
+
+func NsTextSearchSuggest(bukname, text string) []byte {
+	return []byte("hp:search:suggest:" + bukname + ":" + text)
+}
+
+func NsTextSearchSuggestRefs(bukname, id string) []byte {
+	return []byte("hp:search:suggest-refs:" + bukname + ":" + id)
+}
+
+func NsSearchLog(id string) []byte {
+	return []byte("hp:search:log:" + id)
+}
+
+func NsSearchDict(lang, modname string) []byte {
+	return []byte("hp:search:dict:" + lang + ":" + modname)
+}
+
+func NsRedirect(path string) []byte {
+	return []byte("hp:redirect:" + path)
+}
+
+func NsSite(name string) []byte {
+	return []byte("hp:site:" + name)
+}
+
+func NsMenu(name string) []byte {
+	return []byte("hp:menu:" + name)
+}
+
+func NsPageCache(key string) []byte {
+	return []byte("hp:cache:page:" + key)
+}
+
+func NsPageCacheTag(tag, key string) []byte {
+	return []byte("hp:cache:page-tag:" + tag + ":" + key)
+}
+
+func NsPageCacheTagVersion(tag string) []byte {
+	return []byte("hp:cache:page-ver:" + tag)
+}
+
 func ObjPrint(name string, obj interface{}) {
 	js, _ := json.Encode(obj, "  ")
 	fmt.Println(name, string(js))
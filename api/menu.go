@@ -0,0 +1,152 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lessos/lessgo/types"
+)
+
+const (
+	MenuItemUrl   = "url"
+	MenuItemNode  = "node"
+	MenuItemTerm  = "term"
+	MenuItemRoute = "route"
+
+	menuItemDepthMax = 5
+)
+
+// Menu is a named navigation tree, the items link to a node, a term,
+// a route of the module or an external url.
+type Menu struct {
+	types.TypeMeta `json:",inline"`
+	Name           string      `json:"name"`
+	Title          string      `json:"title,omitempty"`
+	Items          []*MenuItem `json:"items,omitempty"`
+	Created        int64       `json:"created,omitempty"`
+	Updated        int64       `json:"updated,omitempty"`
+}
+
+type MenuItem struct {
+	Title   string            `json:"title"`
+	Labels  map[string]string `json:"labels,omitempty"` // lang -> label
+	Type    string            `json:"type"`
+	Url     string            `json:"url,omitempty"`     // url
+	ModName string            `json:"modname,omitempty"` // node, term, route
+	Model   string            `json:"model,omitempty"`   // node, term
+	ID      string            `json:"id,omitempty"`      // node, term
+	Path    string            `json:"path,omitempty"`    // route, e.g. list
+	Weight  int               `json:"weight,omitempty"`
+	Items   []*MenuItem       `json:"items,omitempty"`
+}
+
+type MenuList struct {
+	types.TypeMeta `json:",inline"`
+	Items          []*Menu `json:"items,omitempty"`
+}
+
+func (it *Menu) Valid() error {
+
+	if !SiteNameReg.MatchString(it.Name) {
+		return fmt.Errorf("Invalid Menu Name (%s)", it.Name)
+	}
+
+	return menuItemsValid(it.Items, 1)
+}
+
+func menuItemsValid(items []*MenuItem, depth int) error {
+
+	if depth > menuItemDepthMax {
+		return fmt.Errorf("Menu Items are nested too deep (max %d)", menuItemDepthMax)
+	}
+
+	for _, v := range items {
+		if err := v.Valid(); err != nil {
+			return err
+		}
+		if err := menuItemsValid(v.Items, depth+1); err != nil {
+			return err
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Weight < items[j].Weight
+	})
+
+	return nil
+}
+
+func (it *MenuItem) Valid() error {
+
+	it.Title = strings.TrimSpace(it.Title)
+	if it.Title == "" {
+		return fmt.Errorf("No Title Found in Menu Item")
+	}
+
+	for lang, label := range it.Labels {
+		if label = strings.TrimSpace(label); label == "" {
+			delete(it.Labels, lang)
+		} else if lang2 := strings.ToLower(lang); lang2 != lang {
+			delete(it.Labels, lang)
+			it.Labels[lang2] = label
+		} else {
+			it.Labels[lang] = label
+		}
+	}
+
+	switch it.Type {
+
+	case MenuItemUrl:
+		u, err := url.Parse(it.Url)
+		if err != nil || it.Url == "" {
+			return fmt.Errorf("Invalid Url (%s) in Menu Item (%s)", it.Url, it.Title)
+		}
+		if u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("Invalid Url (%s) in Menu Item (%s)", it.Url, it.Title)
+		}
+
+	case MenuItemNode, MenuItemTerm:
+		if it.ModName == "" || it.Model == "" || it.ID == "" {
+			return fmt.Errorf("No ModName, Model or ID Found in Menu Item (%s)", it.Title)
+		}
+
+	case MenuItemRoute:
+		if it.ModName == "" {
+			return fmt.Errorf("No ModName Found in Menu Item (%s)", it.Title)
+		}
+		it.Path = strings.Trim(filepath.Clean("/"+strings.TrimSpace(it.Path)), "/")
+		if strings.ContainsAny(it.Path, ":*?") {
+			return fmt.Errorf("Invalid Route Path (%s) in Menu Item (%s)", it.Path, it.Title)
+		}
+
+	default:
+		return fmt.Errorf("Invalid Type (%s) in Menu Item (%s)", it.Type, it.Title)
+	}
+
+	return nil
+}
+
+// Label returns the label of the language or the default title
+func (it *MenuItem) Label(lang string) string {
+	if v, ok := it.Labels[strings.ToLower(lang)]; ok {
+		return v
+	}
+	return it.Title
+}
@@ -15,29 +15,35 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/lessos/lessgo/types"
 )
 
 type Node struct {
 	types.TypeMeta     `json:",inline"`
-	SelfLink           string       `json:"self_link,omitempty"`
-	Model              *NodeModel   `json:"model,omitempty"`
-	ID                 string       `json:"id,omitempty"`
-	PID                string       `json:"pid,omitempty"`
-	Status             int16        `json:"status,omitempty"`
-	UserID             string       `json:"userid,omitempty"`
-	Title              string       `json:"title,omitempty"`
-	Created            uint32       `json:"created,omitempty"`
-	Updated            uint32       `json:"updated,omitempty"`
-	Fields             []*NodeField `json:"fields,omitempty"`
-	Terms              []NodeTerm   `json:"terms,omitempty"`
-	ExtAccessCounter   uint32       `json:"ext_access_counter,omitempty"`
-	ExtCommentEnable   bool         `json:"ext_comment_enable,omitempty"`
-	ExtCommentPerEntry bool         `json:"ext_comment_perentry,omitempty"`
-	ExtPermalinkName   string       `json:"ext_permalink_name,omitempty"`
-	ExtNodeRefer       string       `json:"ext_node_refer,omitempty"`
+	SelfLink           string             `json:"self_link,omitempty"`
+	Model              *NodeModel         `json:"model,omitempty"`
+	ID                 string             `json:"id,omitempty"`
+	PID                string             `json:"pid,omitempty"`
+	Status             int16              `json:"status,omitempty"`
+	UserID             string             `json:"userid,omitempty"`
+	Title              string             `json:"title,omitempty"`
+	Created            uint32             `json:"created,omitempty"`
+	Updated            uint32             `json:"updated,omitempty"`
+	Fields             []*NodeField       `json:"fields,omitempty"`
+	Terms              []NodeTerm         `json:"terms,omitempty"`
+	ExtAccessCounter   uint32             `json:"ext_access_counter,omitempty"`
+	ExtCommentEnable   bool               `json:"ext_comment_enable,omitempty"`
+	ExtCommentPerEntry bool               `json:"ext_comment_perentry,omitempty"`
+	ExtPermalinkName   string             `json:"ext_permalink_name,omitempty"`
+	ExtNodeRefer       string             `json:"ext_node_refer,omitempty"`
+	ExtSeo             *NodeSeo           `json:"ext_seo,omitempty"`
+	SearchExcerpt      *NodeSearchExcerpt `json:"search_excerpt,omitempty"`
+	SearchScore        int64              `json:"search_score,omitempty"`
 }
 
 var (
@@ -45,6 +51,60 @@ var (
 	NodeExtNodeReferReg = regexp.MustCompile("^[0-9a-f]{12,16}$")
 )
 
+// NodePreview is a signed and expiring url which renders a node of any
+// status through its route and template
+type NodePreview struct {
+	types.TypeMeta `json:",inline"`
+	Url            string `json:"url,omitempty"`
+	Expired        int64  `json:"expired,omitempty"`
+}
+
+// NodeSeo overrides the head metadata of a node page, the empty values
+// are derived from the title, summary and first image of the node
+type NodeSeo struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Canonical   string `json:"canonical,omitempty"`
+	NoIndex     bool   `json:"noindex,omitempty"`
+	NoFollow    bool   `json:"nofollow,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+var (
+	nodeSeoUrlReg = regexp.MustCompile(`^(https?://[^\s]+|/[^\s]*)$`)
+)
+
+func (it *NodeSeo) Valid() error {
+
+	it.Title = strings.TrimSpace(it.Title)
+	it.Description = strings.TrimSpace(it.Description)
+	it.Canonical = strings.TrimSpace(it.Canonical)
+	it.Image = strings.TrimSpace(it.Image)
+
+	if len(it.Title) > 200 {
+		return errors.New("SEO Title must be less than 200 bytes")
+	}
+
+	if len(it.Description) > 1000 {
+		return errors.New("SEO Description must be less than 1000 bytes")
+	}
+
+	if it.Canonical != "" && !nodeSeoUrlReg.MatchString(it.Canonical) {
+		return fmt.Errorf("Invalid Canonical URL (%s)", it.Canonical)
+	}
+
+	if it.Image != "" && !nodeSeoUrlReg.MatchString(it.Image) {
+		return fmt.Errorf("Invalid Image URL (%s)", it.Image)
+	}
+
+	return nil
+}
+
+func (it *NodeSeo) IsEmpty() bool {
+	return it == nil || (it.Title == "" && it.Description == "" &&
+		it.Canonical == "" && !it.NoIndex && !it.NoFollow && it.Image == "")
+}
+
 func (item *Node) Field(name string) *NodeField {
 	for _, v := range item.Fields {
 		if v.Name == name {
@@ -54,11 +114,89 @@ func (item *Node) Field(name string) *NodeField {
 	return nil
 }
 
+type NodeSearchExcerpt struct {
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+type NodeSearchResult struct {
+	ModName   string  `json:"modname"`
+	SrvName   string  `json:"srvname,omitempty"`
+	Model     string  `json:"model"`
+	Permalink string  `json:"permalink,omitempty"`
+	Score     float64 `json:"score"`
+	Node      Node    `json:"node"`
+}
+
+type NodeSearchResultList struct {
+	types.TypeMeta `json:",inline"`
+	Meta           types.ListMeta     `json:"meta,omitempty"`
+	Items          []NodeSearchResult `json:"items,omitempty"`
+	SearchSuggest  string             `json:"search_suggest,omitempty"`
+	SearchLogID    string             `json:"search_log_id,omitempty"`
+}
+
+type NodeSearchSuggest struct {
+	Text      string `json:"text"`
+	Type      string `json:"type"`
+	ModName   string `json:"modname,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Permalink string `json:"permalink,omitempty"`
+}
+
+type NodeSearchSuggestList struct {
+	types.TypeMeta `json:",inline"`
+	Items          []NodeSearchSuggest `json:"items,omitempty"`
+	DidYouMean     string              `json:"did_you_mean,omitempty"`
+}
+
+type NodeSearchIndexStatus struct {
+	Bucket       string `json:"bucket"`
+	ModName      string `json:"modname"`
+	Model        string `json:"model"`
+	Engine       string `json:"engine"`
+	Documents    int64  `json:"documents"`
+	FullIndexed  int64  `json:"full_indexed,omitempty"`
+	DeltaIndexed int64  `json:"delta_indexed,omitempty"`
+	DeltaPending int64  `json:"delta_pending,omitempty"`
+	SyncUpdated  int64  `json:"sync_updated,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+type NodeSearchIndexStatusList struct {
+	types.TypeMeta `json:",inline"`
+	Items          []*NodeSearchIndexStatus `json:"items,omitempty"`
+}
+
+type NodeSearchIndexCheck struct {
+	types.TypeMeta `json:",inline"`
+	Bucket         string   `json:"bucket"`
+	Rows           int64    `json:"rows"`
+	Documents      int64    `json:"documents"`
+	Missing        []string `json:"missing,omitempty"`
+	Stale          []string `json:"stale,omitempty"`
+	Repaired       int64    `json:"repaired,omitempty"`
+}
+
 type NodeList struct {
 	types.TypeMeta `json:",inline"`
-	Meta           types.ListMeta `json:"meta,omitempty"`
-	Model          *NodeModel     `json:"model,omitempty"`
-	Items          []Node         `json:"items,omitempty"`
+	Meta           types.ListMeta   `json:"meta,omitempty"`
+	Model          *NodeModel       `json:"model,omitempty"`
+	Items          []Node           `json:"items,omitempty"`
+	Facets         []*NodeListFacet `json:"facets,omitempty"`
+	SearchSuggest  string           `json:"search_suggest,omitempty"`
+	SearchLogID    string           `json:"search_log_id,omitempty"`
+}
+
+type NodeListFacet struct {
+	Name  string               `json:"name"`
+	Items []*NodeListFacetItem `json:"items,omitempty"`
+}
+
+type NodeListFacetItem struct {
+	ID    uint32 `json:"id"`
+	Title string `json:"title,omitempty"`
+	Count uint64 `json:"count"`
 }
 
 type NodeFieldType string
@@ -123,3 +261,52 @@ type NodeTerm struct {
 	Type  string `json:"type,omitempty"`
 	Items []Term `json:"items,omitempty"`
 }
+
+type SearchLogEntry struct {
+	ID      string `json:"id"`
+	Query   string `json:"query"`
+	ModName string `json:"modname,omitempty"`
+	Model   string `json:"model,omitempty"`
+	Results uint64 `json:"results"`
+	Clicked string `json:"clicked,omitempty"`
+	Clicks  int64  `json:"clicks,omitempty"`
+	Created int64  `json:"created"`
+}
+
+type SearchLogQueryStats struct {
+	Query   string `json:"query"`
+	Count   int64  `json:"count"`
+	Clicks  int64  `json:"clicks"`
+	Results uint64 `json:"results"`
+}
+
+type SearchLogModuleStats struct {
+	ModName   string  `json:"modname"`
+	Searches  int64   `json:"searches"`
+	Zeros     int64   `json:"zeros"`
+	Clicked   int64   `json:"clicked"`
+	ClickRate float64 `json:"click_rate"`
+}
+
+type SearchLogStats struct {
+	types.TypeMeta `json:",inline"`
+	Since          int64                   `json:"since"`
+	Searches       int64                   `json:"searches"`
+	TopQueries     []*SearchLogQueryStats  `json:"top_queries,omitempty"`
+	ZeroQueries    []*SearchLogQueryStats  `json:"zero_queries,omitempty"`
+	Modules        []*SearchLogModuleStats `json:"modules,omitempty"`
+}
+
+type SearchDict struct {
+	types.TypeMeta `json:",inline"`
+	Lang           string     `json:"lang"`
+	ModName        string     `json:"modname,omitempty"`
+	Synonyms       [][]string `json:"synonyms,omitempty"`
+	Stopwords      []string   `json:"stopwords,omitempty"`
+	Updated        int64      `json:"updated,omitempty"`
+}
+
+type SearchDictList struct {
+	types.TypeMeta `json:",inline"`
+	Items          []*SearchDict `json:"items,omitempty"`
+}
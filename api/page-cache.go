@@ -0,0 +1,29 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+// PageCacheEntry is a rendered page of the frontend output cache, the
+// access counters of the page are counted again on every hit.
+type PageCacheEntry struct {
+	ContentType  string   `json:"content_type,omitempty"`
+	ETag         string   `json:"etag,omitempty"`
+	LastModified string   `json:"last_modified,omitempty"`
+	CacheControl string   `json:"cache_control,omitempty"`
+	RobotsTag    string   `json:"robots_tag,omitempty"`
+	Body         string   `json:"body"`
+	Tags         []string `json:"tags,omitempty"`
+	Counters     []string `json:"counters,omitempty"`
+	Created      int64    `json:"created"`
+}
@@ -0,0 +1,36 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"github.com/lessos/lessgo/types"
+)
+
+// Redirect maps an old request path to its new location with a 301
+// response. A Prefix redirect also applies to all of the sub paths.
+type Redirect struct {
+	types.TypeMeta `json:",inline"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Prefix         bool   `json:"prefix,omitempty"`
+	Auto           bool   `json:"auto,omitempty"`
+	Created        int64  `json:"created,omitempty"`
+	Updated        int64  `json:"updated,omitempty"`
+}
+
+type RedirectList struct {
+	types.TypeMeta `json:",inline"`
+	Items          []*Redirect `json:"items,omitempty"`
+}
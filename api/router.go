@@ -5,6 +5,14 @@
 package api
 
 import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
 	"github.com/lessos/lessgo/types"
 )
 
@@ -13,13 +21,547 @@ type Router struct {
 	// DefaultPagelet string  `json:"defaultPagelet,omitempty"` // e.g. index.tpl
 }
 
+// Route.Path segments:
+//
+//	static   literal segment, e.g. view
+//	:name    param segment
+//	:name?   optional param segment, only allowed at the tail
+//	*name    wildcard, captures the rest of the path, must be the last segment
+//
+// Route.Params maps a param name to its constraint:
+//
+//	int, slug, enum:a|b|c, regex:<expr>
+//
+// The other values of Route.Params are free-form legacy values, they are
+// ignored by the matching.
 type Route struct {
 	types.TypeMeta `json:",inline"`
 	Path           string            `json:"path"` // e.g. /app/:id
 	DataAction     string            `json:"dataAction,omitempty"`
 	Template       string            `json:"template,omitempty"` // e.g. index.tpl
 	Params         map[string]string `json:"params,omitempty"`
+	Priority       int               `json:"priority,omitempty"`
+	Cache          *RouteCache       `json:"cache,omitempty"`
+	Feed           *RouteFeed        `json:"feed,omitempty"`
+	Robots         *RouteRobots      `json:"robots,omitempty"`
 	Tree           []string          `json:"-"`
 	ModName        string            `json:"modname,omitempty"`
 	Default        bool              `json:"default,omitempty"`
+	segs           []*routeSegment
+	legacy         bool
+}
+
+const (
+	RouteCachePublic  = "public"
+	RouteCachePrivate = "private"
+	RouteCacheNoStore = "no-store"
+)
+
+// RouteCache is the http cache policy of the pages rendered by a route
+type RouteCache struct {
+	Scope  string `json:"scope,omitempty"` // public, private or no-store
+	MaxAge int    `json:"maxAge,omitempty"`
+}
+
+func (it *RouteCache) Valid() error {
+	switch it.Scope {
+	case "", RouteCachePublic, RouteCachePrivate, RouteCacheNoStore:
+	default:
+		return fmt.Errorf("Invalid Cache Scope (%s)", it.Scope)
+	}
+	if it.MaxAge < 0 || it.MaxAge > 31536000 {
+		return errors.New("Invalid Cache Max-Age, the range is 0 ~ 31536000")
+	}
+	return nil
+}
+
+func (it *RouteCache) Equal(it2 *RouteCache) bool {
+	if it == nil || it2 == nil {
+		return it == it2
+	}
+	return it.Scope == it2.Scope && it.MaxAge == it2.MaxAge
+}
+
+const (
+	RouteFeedRss         = "rss"
+	RouteFeedAtom        = "atom"
+	RouteFeedSummary     = "summary"
+	RouteFeedFullContent = "full"
+)
+
+// RouteFeed renders the node.list of the route as a RSS 2.0 or Atom feed
+type RouteFeed struct {
+	Format  string `json:"format"`            // rss or atom
+	Content string `json:"content,omitempty"` // summary or full
+}
+
+func (it *RouteFeed) Valid() error {
+	if it.Format != RouteFeedRss && it.Format != RouteFeedAtom {
+		return fmt.Errorf("Invalid Feed Format (%s)", it.Format)
+	}
+	if it.Content != "" && it.Content != RouteFeedSummary && it.Content != RouteFeedFullContent {
+		return fmt.Errorf("Invalid Feed Content (%s)", it.Content)
+	}
+	return nil
+}
+
+func (it *RouteFeed) Equal(it2 *RouteFeed) bool {
+	if it == nil || it2 == nil {
+		return it == it2
+	}
+	return it.Format == it2.Format && it.Content == it2.Content
+}
+
+// RouteRobots is the crawler policy of the pages rendered by a route, it
+// is sent as the X-Robots-Tag header
+type RouteRobots struct {
+	NoIndex  bool `json:"noindex,omitempty"`
+	NoFollow bool `json:"nofollow,omitempty"`
+}
+
+func (it *RouteRobots) Directive() string {
+	if it == nil {
+		return ""
+	}
+	return RobotsDirective(it.NoIndex, it.NoFollow)
+}
+
+func (it *RouteRobots) Equal(it2 *RouteRobots) bool {
+	if it == nil || it2 == nil {
+		return it == it2
+	}
+	return *it == *it2
+}
+
+// RobotsDirective returns the value of a robots meta tag or X-Robots-Tag
+// header, e.g. "noindex, nofollow"
+func RobotsDirective(noindex, nofollow bool) string {
+	switch {
+	case noindex && nofollow:
+		return "noindex, nofollow"
+	case noindex:
+		return "noindex"
+	case nofollow:
+		return "nofollow"
+	}
+	return ""
+}
+
+const (
+	routeSegStatic   = 0
+	routeSegParam    = 1
+	routeSegWildcard = 2
+)
+
+type routeSegment struct {
+	Type       int
+	Name       string
+	Optional   bool
+	Constraint string
+	kind       string
+	enums      types.ArrayString
+	re         *regexp.Regexp
+}
+
+var (
+	routeSlugPattern = regexp.MustCompile("^[a-z0-9]+(-[a-z0-9]+)*$")
+)
+
+func routeConstraintParse(seg *routeSegment, cons string) error {
+
+	cons = strings.TrimSpace(cons)
+
+	switch {
+
+	case cons == "int", cons == "slug":
+		seg.kind, seg.Constraint = cons, cons
+
+	case strings.HasPrefix(cons, "enum:"):
+		enums := types.ArrayString{}
+		for _, v := range strings.Split(cons[len("enum:"):], "|") {
+			if v = strings.TrimSpace(v); v != "" {
+				enums.Set(v)
+			}
+		}
+		if len(enums) < 1 {
+			return fmt.Errorf("Empty enum constraint of param (%s)", seg.Name)
+		}
+		seg.kind, seg.Constraint, seg.enums = "enum", cons, enums
+
+	case strings.HasPrefix(cons, "regex:"):
+		expr := cons[len("regex:"):]
+		if expr == "" {
+			return fmt.Errorf("Empty regex constraint of param (%s)", seg.Name)
+		}
+		if !strings.HasPrefix(expr, "^") {
+			expr = "^(?:" + expr + ")$"
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("Invalid regex constraint of param (%s) : %s", seg.Name, err.Error())
+		}
+		seg.kind, seg.Constraint, seg.re = "regex", cons, re
+	}
+
+	return nil
+}
+
+func (seg *routeSegment) match(v string) bool {
+
+	switch seg.Type {
+
+	case routeSegStatic:
+		return seg.Name == v
+
+	case routeSegParam:
+		switch seg.kind {
+
+		case "int":
+			_, err := strconv.ParseUint(v, 10, 64)
+			return err == nil
+
+		case "slug":
+			return routeSlugPattern.MatchString(v)
+
+		case "enum":
+			return seg.enums.Has(v)
+
+		case "regex":
+			return seg.re.MatchString(v)
+		}
+		return v != ""
+	}
+
+	return true
+}
+
+// covers reports whether every value accepted by b is accepted by seg
+func (seg *routeSegment) covers(b *routeSegment) bool {
+
+	if seg.Type == routeSegStatic {
+		return b.Type == routeSegStatic && b.Name == seg.Name
+	}
+
+	if seg.kind == "" {
+		return true
+	}
+
+	if b.Type == routeSegStatic {
+		return seg.match(b.Name)
+	}
+
+	if b.kind == "" {
+		return false
+	}
+
+	if seg.Constraint == b.Constraint ||
+		(seg.kind == "slug" && b.kind == "int") {
+		return true
+	}
+
+	if b.kind == "enum" {
+		for _, v := range b.enums {
+			if !seg.match(v) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// RouteCompile parses the Path and Params of a route into matchable segments.
+// A path which can not be parsed falls back to the legacy matching of the
+// plain segments, and a malformed constraint is skipped, so that a route
+// loaded from an existing spec never stops serving, the error is returned
+// to be logged or rejected on save.
+func RouteCompile(route *Route) error {
+
+	route.Tree = strings.Split(strings.Trim(filepath.Clean(route.Path), "/"), "/")
+
+	segs, err := routeSegmentsParse(route.Tree)
+	if err != nil {
+		route.segs, route.legacy = routeSegmentsLegacy(route.Tree), true
+		return err
+	}
+
+	for name, cons := range route.Params {
+
+		for _, seg := range segs {
+			if seg.Type != routeSegParam || seg.Name != name {
+				continue
+			}
+			if err2 := routeConstraintParse(seg, cons); err2 != nil && err == nil {
+				err = err2
+			}
+			break
+		}
+	}
+
+	route.segs, route.legacy = segs, false
+
+	return err
+}
+
+func routeSegmentsParse(tree []string) ([]*routeSegment, error) {
+
+	var (
+		segs     = []*routeSegment{}
+		names    = types.ArrayString{}
+		optional = false
+	)
+
+	for i, node := range tree {
+
+		if node == "" {
+			if len(tree) == 1 {
+				break
+			}
+			return nil, errors.New("Empty path segment")
+		}
+
+		seg := &routeSegment{
+			Type: routeSegStatic,
+			Name: node,
+		}
+
+		switch node[0] {
+
+		case ':':
+			seg.Type, seg.Name = routeSegParam, node[1:]
+			if strings.HasSuffix(seg.Name, "?") {
+				seg.Name, seg.Optional = seg.Name[:len(seg.Name)-1], true
+			}
+
+		case '*':
+			if i+1 != len(tree) {
+				return nil, fmt.Errorf("Wildcard (%s) must be the last segment", node)
+			}
+			seg.Type, seg.Name = routeSegWildcard, node[1:]
+		}
+
+		if seg.Type == routeSegStatic {
+			if strings.ContainsAny(node, ":*?") {
+				return nil, fmt.Errorf("Invalid path segment (%s)", node)
+			}
+		} else {
+			if seg.Name == "" || strings.ContainsAny(seg.Name, ":*?") {
+				return nil, fmt.Errorf("Invalid param segment (%s)", node)
+			}
+			if names.Has(seg.Name) {
+				return nil, fmt.Errorf("Duplicate param name (%s)", seg.Name)
+			}
+			names.Set(seg.Name)
+		}
+
+		if optional && !seg.Optional && seg.Type != routeSegWildcard {
+			return nil, fmt.Errorf("Segment (%s) can not follow an optional segment", node)
+		}
+		if seg.Optional {
+			optional = true
+		}
+
+		segs = append(segs, seg)
+	}
+
+	return segs, nil
+}
+
+// routeSegmentsLegacy returns the plain static and :param segments
+func routeSegmentsLegacy(tree []string) []*routeSegment {
+
+	segs := []*routeSegment{}
+
+	for _, node := range tree {
+		if len(node) < 1 {
+			break
+		}
+		if node[0] == ':' {
+			segs = append(segs, &routeSegment{
+				Type: routeSegParam,
+				Name: node[1:],
+			})
+		} else {
+			segs = append(segs, &routeSegment{
+				Type: routeSegStatic,
+				Name: node,
+			})
+		}
+	}
+
+	return segs
+}
+
+// Match tests the request path segments against the route. Extra trailing
+// segments are accepted, as with the plain segment routes. The route must
+// be compiled by RouteCompile first.
+func (route *Route) Match(rt []string) (map[string]string, bool) {
+
+	// the root path is only served as the Default route
+	if len(route.segs) == 0 {
+		return nil, false
+	}
+
+	params := map[string]string{}
+
+	if route.legacy {
+		for i, seg := range route.segs {
+			if i >= len(rt) {
+				return nil, false
+			}
+			if seg.Type == routeSegParam {
+				params[seg.Name] = rt[i]
+			} else if seg.Name != rt[i] {
+				return nil, false
+			}
+		}
+		return params, true
+	}
+
+	for i, seg := range route.segs {
+
+		if seg.Type == routeSegWildcard {
+			if i < len(rt) {
+				params[seg.Name] = strings.Join(rt[i:], "/")
+			} else {
+				params[seg.Name] = ""
+			}
+			return params, true
+		}
+
+		if i >= len(rt) || rt[i] == "" {
+			if seg.Optional {
+				continue
+			}
+			return nil, false
+		}
+
+		if !seg.match(rt[i]) {
+			return nil, false
+		}
+
+		if seg.Type == routeSegParam {
+			params[seg.Name] = rt[i]
+		}
+	}
+
+	return params, true
+}
+
+// Shadows reports whether every request path matched by b is already
+// matched by the route, so that b can never match if ordered after it.
+func (route *Route) Shadows(b *Route) bool {
+
+	if len(route.segs) == 0 || len(b.segs) == 0 || route.legacy || b.legacy {
+		return false
+	}
+
+	for i, seg := range route.segs {
+
+		if seg.Type == routeSegWildcard {
+			return true
+		}
+
+		if i >= len(b.segs) {
+			if seg.Optional {
+				continue
+			}
+			return false
+		}
+
+		bs := b.segs[i]
+
+		if bs.Type == routeSegWildcard {
+			// the tail of b may be anything, including nothing
+			if !seg.Optional || seg.kind != "" {
+				return false
+			}
+			continue
+		}
+
+		if bs.Optional && !seg.Optional {
+			return false
+		}
+
+		if !seg.covers(bs) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RoutesSort moves the routes with a higher Priority ahead, the routes of
+// the same Priority keep the declaration order.
+func RoutesSort(routes []Route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Priority > routes[j].Priority
+	})
+}
+
+// Build returns the path of the route with the params, without the srvname
+// prefix. The optional tail params and the wildcard may be omitted, and
+// every given value must satisfy the constraint of its param. The route must
+// be compiled by RouteCompile first.
+func (route *Route) Build(params map[string]string) (string, bool) {
+
+	if route.segs == nil {
+		return "", false
+	}
+
+	paths := []string{}
+
+	for _, seg := range route.segs {
+
+		if seg.Type == routeSegStatic {
+			paths = append(paths, seg.Name)
+			continue
+		}
+
+		v, ok := params[seg.Name]
+		if !ok || v == "" {
+			if seg.Optional || seg.Type == routeSegWildcard {
+				break
+			}
+			return "", false
+		}
+
+		if seg.Type == routeSegParam && !seg.match(v) {
+			return "", false
+		}
+
+		paths = append(paths, v)
+
+		if seg.Type == routeSegWildcard {
+			break
+		}
+	}
+
+	return "/" + strings.Join(paths, "/"), true
+}
+
+// RoutesValidate compiles all routes and rejects those that can never match
+func RoutesValidate(routes []Route) error {
+
+	for i := range routes {
+		if err := RouteCompile(&routes[i]); err != nil {
+			return fmt.Errorf("Route (%s) : %s", routes[i].Path, err.Error())
+		}
+	}
+
+	ls := make([]Route, len(routes))
+	copy(ls, routes)
+	RoutesSort(ls)
+
+	for i := 1; i < len(ls); i++ {
+		for j := 0; j < i; j++ {
+			if ls[j].Shadows(&ls[i]) {
+				return fmt.Errorf("Route (%s) can never match, it is shadowed by (%s)",
+					ls[i].Path, ls[j].Path)
+			}
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,219 @@
+// Copyright 2014 lessOS.com. All rights reserved.
+//
+// Use of this source code is governed by the Apache 2.0
+// license that can be found in the LICENSE file.
+
+package api
+
+import (
+	"strings"
+	"testing"
+)
+
+func routeTestNew(t *testing.T, path string, params map[string]string) *Route {
+	route := &Route{
+		Path:   path,
+		Params: params,
+	}
+	if err := RouteCompile(route); err != nil {
+		t.Fatalf("Failed on RouteCompile %s : %s", path, err.Error())
+	}
+	return route
+}
+
+func routeTestPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func TestRouteMatch(t *testing.T) {
+
+	for _, v := range []struct {
+		path   string
+		params map[string]string
+		req    string
+		ok     bool
+		args   map[string]string
+	}{
+		{"list", nil, "list", true, map[string]string{}},
+		{"list", nil, "list/extra", true, map[string]string{}},
+		{"list", nil, "view", false, nil},
+		{"view/:id", nil, "view/abc", true, map[string]string{"id": "abc"}},
+		{"view/:id", nil, "view", false, nil},
+		{"view/:id", map[string]string{"id": "int"}, "view/123", true, map[string]string{"id": "123"}},
+		{"view/:id", map[string]string{"id": "int"}, "view/abc", false, nil},
+		{"view/:id", map[string]string{"id": "slug"}, "view/a-b-1", true, map[string]string{"id": "a-b-1"}},
+		{"view/:id", map[string]string{"id": "slug"}, "view/A_b", false, nil},
+		{"tag/:t", map[string]string{"t": "enum:go|rust"}, "tag/go", true, map[string]string{"t": "go"}},
+		{"tag/:t", map[string]string{"t": "enum:go|rust"}, "tag/java", false, nil},
+		{"v/:x", map[string]string{"x": "regex:[0-9]{4}"}, "v/2019", true, map[string]string{"x": "2019"}},
+		{"v/:x", map[string]string{"x": "regex:[0-9]{4}"}, "v/20190", false, nil},
+		{"list/:page?", nil, "list", true, map[string]string{}},
+		{"list/:page?", nil, "list/2", true, map[string]string{"page": "2"}},
+		{"files/*rest", nil, "files/a/b/c", true, map[string]string{"rest": "a/b/c"}},
+		{"files/*rest", nil, "files", true, map[string]string{"rest": ""}},
+		// legacy free-form params are ignored
+		{"view/:id", map[string]string{"id": "the entry id"}, "view/abc", true, map[string]string{"id": "abc"}},
+		{"view/:id", map[string]string{"name": "int"}, "view/abc", true, map[string]string{"id": "abc"}},
+		// the root path is only served as the default route
+		{"/", nil, "list", false, nil},
+		{"", nil, "", false, nil},
+	} {
+
+		route := routeTestNew(t, v.path, v.params)
+
+		args, ok := route.Match(routeTestPath(v.req))
+		if ok != v.ok {
+			t.Fatalf("Failed on Match %s %v with %s, expect %v", v.path, v.params, v.req, v.ok)
+		}
+
+		if !ok {
+			continue
+		}
+
+		if len(args) != len(v.args) {
+			t.Fatalf("Failed on Match %s with %s, expect %v, got %v", v.path, v.req, v.args, args)
+		}
+		for k, v2 := range v.args {
+			if args[k] != v2 {
+				t.Fatalf("Failed on Match %s with %s, expect %v, got %v", v.path, v.req, v.args, args)
+			}
+		}
+	}
+}
+
+func TestRouteCompileLegacy(t *testing.T) {
+
+	// a malformed constraint is reported but does not stop the route
+	route := &Route{
+		Path:   "view/:id",
+		Params: map[string]string{"id": "regex:[a-"},
+	}
+	if err := RouteCompile(route); err == nil {
+		t.Fatal("Failed on RouteCompile, expect error of the regex constraint")
+	}
+	if _, ok := route.Match(routeTestPath("view/abc")); !ok {
+		t.Fatal("Failed on Match with a malformed constraint")
+	}
+
+	// a path which can not be parsed is matched as plain segments
+	route = &Route{
+		Path: "view/:id/:id",
+	}
+	if err := RouteCompile(route); err == nil {
+		t.Fatal("Failed on RouteCompile, expect error of the duplicate param")
+	}
+	if _, ok := route.Match(routeTestPath("view/a/b")); !ok {
+		t.Fatal("Failed on Match of the legacy route")
+	}
+}
+
+func TestRouteShadows(t *testing.T) {
+
+	for _, v := range []struct {
+		a, b    string
+		aParams map[string]string
+		bParams map[string]string
+		shadows bool
+	}{
+		{"view/:id", "view/:id", nil, nil, true},
+		{"view/:id", "view/new", nil, nil, true},
+		{"view/new", "view/:id", nil, nil, false},
+		{"view/:id", "view/:id", map[string]string{"id": "int"}, nil, false},
+		{"view/:id", "view/:id", map[string]string{"id": "slug"}, map[string]string{"id": "int"}, true},
+		{"tag/:t", "tag/:t", nil, map[string]string{"t": "enum:a|b"}, true},
+		{"tag/:t", "tag/:t", map[string]string{"t": "enum:a|b"}, map[string]string{"t": "enum:a"}, true},
+		{"tag/:t", "tag/:t", map[string]string{"t": "enum:a"}, map[string]string{"t": "enum:a|b"}, false},
+		{"files/*rest", "files/a/b", nil, nil, true},
+		{"list", "list/:page?", nil, nil, true},
+		{"list/:page", "list/:page?", nil, nil, false},
+		{"/", "list", nil, nil, false},
+		{"list", "/", nil, nil, false},
+	} {
+
+		a := routeTestNew(t, v.a, v.aParams)
+		b := routeTestNew(t, v.b, v.bParams)
+
+		if a.Shadows(b) != v.shadows {
+			t.Fatalf("Failed on Shadows %s %v > %s %v, expect %v",
+				v.a, v.aParams, v.b, v.bParams, v.shadows)
+		}
+	}
+}
+
+func TestRoutesSort(t *testing.T) {
+
+	routes := []Route{
+		{Path: "view/:id"},
+		{Path: "view/new"},
+		{Path: "list", Priority: 1},
+		{Path: "files/*rest"},
+		{Path: "about", Priority: 1},
+	}
+	RoutesSort(routes)
+
+	paths := []string{}
+	for _, v := range routes {
+		paths = append(paths, v.Path)
+	}
+
+	if s := strings.Join(paths, " "); s != "list about view/:id view/new files/*rest" {
+		t.Fatalf("Failed on RoutesSort, got %s", s)
+	}
+}
+
+func TestRoutesValidate(t *testing.T) {
+
+	if err := RoutesValidate([]Route{
+		{Path: "view/new"},
+		{Path: "view/:id"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RoutesValidate([]Route{
+		{Path: "view/:id"},
+		{Path: "view/new"},
+	}); err == nil {
+		t.Fatal("Failed on RoutesValidate, expect error of the shadowed route")
+	}
+
+	if err := RoutesValidate([]Route{
+		{Path: "view/:id"},
+		{Path: "view/new", Priority: 1},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouteBuild(t *testing.T) {
+
+	for _, v := range []struct {
+		path   string
+		params map[string]string
+		args   map[string]string
+		ok     bool
+		ret    string
+	}{
+		{"list", nil, nil, true, "/list"},
+		{"/", nil, nil, true, "/"},
+		{"view/:id", nil, map[string]string{"id": "abc.html"}, true, "/view/abc.html"},
+		{"view/:id", nil, nil, false, ""},
+		{"view/:id", map[string]string{"id": "int"}, map[string]string{"id": "abc.html"}, false, ""},
+		{"view/:id", map[string]string{"id": "int"}, map[string]string{"id": "12"}, true, "/view/12"},
+		{"view/:doc/:page", nil, map[string]string{"doc": "a"}, false, ""},
+		{"view/:doc/:page", nil, map[string]string{"doc": "a", "page": "b"}, true, "/view/a/b"},
+		{"list/:page?", nil, nil, true, "/list"},
+		{"list/:page?", nil, map[string]string{"page": "2"}, true, "/list/2"},
+		{"files/*rest", nil, nil, true, "/files"},
+		{"files/*rest", nil, map[string]string{"rest": "a/b"}, true, "/files/a/b"},
+	} {
+
+		route := routeTestNew(t, v.path, v.params)
+
+		ret, ok := route.Build(v.args)
+		if ok != v.ok || ret != v.ret {
+			t.Fatalf("Failed on Build %s %v with %v, expect %v %s, got %v %s",
+				v.path, v.params, v.args, v.ok, v.ret, ok, ret)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lessos/lessgo/types"
+)
+
+var (
+	SiteNameReg = regexp.MustCompile("^[a-z][a-z0-9_-]{1,29}$")
+	siteHostReg = regexp.MustCompile(`^[a-z0-9]([a-z0-9\-\.]{0,250})(:[0-9]{1,5})?$`)
+)
+
+// Site serves the matched hostnames from one process and database. The
+// empty values fall back to the global settings.
+type Site struct {
+	types.TypeMeta `json:",inline"`
+	Name           string      `json:"name"`
+	Title          string      `json:"title,omitempty"`
+	Hosts          []string    `json:"hosts"`
+	DefaultPath    string      `json:"default_path,omitempty"` // e.g. /blog
+	Modules        []string    `json:"modules,omitempty"`      // the allowed srvnames
+	Languages      string      `json:"languages,omitempty"`    // e.g. en,zh-cn
+	ThemeConfig    string      `json:"theme_config,omitempty"` // toml
+	Configs        []SysConfig `json:"configs,omitempty"`
+	Created        int64       `json:"created,omitempty"`
+	Updated        int64       `json:"updated,omitempty"`
+}
+
+type SiteList struct {
+	types.TypeMeta `json:",inline"`
+	Items          []*Site `json:"items,omitempty"`
+}
+
+func SiteHostFilter(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+func (it *Site) Valid() error {
+
+	if !SiteNameReg.MatchString(it.Name) {
+		return fmt.Errorf("Invalid Site Name (%s)", it.Name)
+	}
+
+	if len(it.Hosts) == 0 {
+		return fmt.Errorf("No Hosts Found in Site (%s)", it.Name)
+	}
+
+	for i, host := range it.Hosts {
+		host = SiteHostFilter(host)
+		if !siteHostReg.MatchString(host) {
+			return fmt.Errorf("Invalid Host (%s)", host)
+		}
+		it.Hosts[i] = host
+	}
+
+	if it.DefaultPath != "" {
+		it.DefaultPath = filepath.Clean("/" + strings.TrimSpace(it.DefaultPath))
+		if it.DefaultPath == "/" {
+			it.DefaultPath = ""
+		}
+	}
+
+	for i, v := range it.Modules {
+		name, err := SrvNameFilter(v)
+		if err != nil {
+			return err
+		}
+		it.Modules[i] = name
+	}
+
+	if it.Languages != "" {
+		it.Languages = LangsStringFilter(it.Languages)
+	}
+
+	for _, v := range it.Configs {
+		if v.Key == "" {
+			return fmt.Errorf("Invalid Config Key in Site (%s)", it.Name)
+		}
+	}
+
+	return nil
+}
+
+// ModuleAllowed returns true if the srvname is served by the site
+func (it *Site) ModuleAllowed(srvname string) bool {
+	if len(it.Modules) == 0 {
+		return true
+	}
+	for _, v := range it.Modules {
+		if v == srvname {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigFetch returns the site override of the SysConfig key
+func (it *Site) ConfigFetch(key string) (string, bool) {
+	for _, v := range it.Configs {
+		if v.Key == key {
+			return v.Value, true
+		}
+	}
+	return "", false
+}
@@ -106,6 +106,7 @@ type NodeModel struct {
 	Fields         []FieldModel     `json:"fields,omitempty"`
 	Terms          []TermModel      `json:"terms,omitempty"`
 	Extensions     SpecExtensions   `json:"extensions,omitempty"`
+	StructuredData *StructuredData  `json:"structured_data,omitempty"`
 }
 
 func (item *NodeModel) Field(name string) *FieldModel {
@@ -117,6 +118,16 @@ func (item *NodeModel) Field(name string) *FieldModel {
 	return nil
 }
 
+// Private returns true if the field is marked by the attr private=true,
+// the private fields such as the credentials of a repository are never
+// written to the public outputs.
+func (it *FieldModel) Private() bool {
+	if v := it.Attrs.Get("private"); v != nil {
+		return v.String() == "true"
+	}
+	return false
+}
+
 var (
 	PermalinkNameReg = regexp.MustCompile("^[0-9a-z_-]{1,100}$")
 )
@@ -140,6 +151,65 @@ type SpecExtensions struct {
 	NodeRefer       string `json:"node_refer,omitempty"`
 	NodeSubRefer    string `json:"node_sub_refer,omitempty"`
 	TextSearch      bool   `json:"text_search,omitempty"`
+	Seo             bool   `json:"seo,omitempty"`
+}
+
+const (
+	StructuredDataArticle     = "Article"
+	StructuredDataBlogPosting = "BlogPosting"
+	StructuredDataTechArticle = "TechArticle"
+)
+
+// StructuredData maps the nodes of a model to a schema.org type, the
+// Headline/Description/Body values are field names and Keywords/Section
+// are term names of the model
+type StructuredData struct {
+	Type        string `json:"type"`
+	Headline    string `json:"headline,omitempty"`
+	Description string `json:"description,omitempty"`
+	Body        string `json:"body,omitempty"`
+	Keywords    string `json:"keywords,omitempty"`
+	Section     string `json:"section,omitempty"`
+}
+
+func (it *StructuredData) Valid(model *NodeModel) error {
+
+	switch it.Type {
+	case StructuredDataArticle, StructuredDataBlogPosting, StructuredDataTechArticle:
+	default:
+		return fmt.Errorf("Invalid Structured Data Type (%s)", it.Type)
+	}
+
+	for _, name := range []string{it.Headline, it.Description, it.Body} {
+		if name != "" && model.Field(name) == nil {
+			return fmt.Errorf("Structured Data Field (%s) Not Found", name)
+		}
+	}
+
+	for _, name := range []string{it.Keywords, it.Section} {
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, term := range model.Terms {
+			if term.Meta.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Structured Data Term (%s) Not Found", name)
+		}
+	}
+
+	return nil
+}
+
+func (it *StructuredData) Equal(it2 *StructuredData) bool {
+	if it == nil || it2 == nil {
+		return it == it2
+	}
+	return *it == *it2
 }
 
 type NodeModelList struct {
@@ -0,0 +1,619 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hooto/httpsrv"
+	"github.com/lessos/lessgo/encoding/json"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+
+	cdef "github.com/hooto/hpress/websrv/frontend"
+	cmod "github.com/hooto/hpress/websrv/module"
+)
+
+const exportUsage = `usage: server export [options] <output-dir>
+
+options:
+  -incremental    only re-export the nodes changed since the last export,
+                  the list pages of their modules, and the pages which have
+                  not been exported yet, the pages of removed nodes are deleted
+  -max-pages <n>  stop after n pages (default 100000)
+
+the pages are rendered by a local frontend instance, the server should be
+stopped before running the command.`
+
+const exportManifestFile = ".hpress-export.json"
+
+var (
+	exportLinkReg     = regexp.MustCompile(`(href|src)=("[^"]*"|'[^']*')`)
+	exportCssUrlReg   = regexp.MustCompile(`url\(\s*("[^"]*"|'[^']*'|[^)'"]*)\s*\)`)
+	exportQueryValReg = regexp.MustCompile(`^[0-9a-zA-Z_\-]{1,40}$`)
+	exportAssetPaths  = []string{"/hp/~/", "/hp/s2/", "/hp/-/"}
+)
+
+type exportManifest struct {
+	Version  string            `json:"version"`
+	Exported int64             `json:"exported"`
+	Pages    map[string]string `json:"pages"` // file: url
+	Nodes    map[string]string `json:"nodes"` // file: modname of the node pages
+}
+
+type exporter struct {
+	dir         string
+	server      string
+	incremental bool
+	maxPages    int
+	manifest    exportManifest
+	client      *http.Client
+	queue       []string
+	queued      map[string]bool
+	seeds       map[string]bool
+	pages       int
+	assets      int
+	removed     int
+}
+
+func exportCommand(args []string) error {
+
+	var (
+		fs          = flag.NewFlagSet("export", flag.ContinueOnError)
+		incremental = fs.Bool("incremental", false, "")
+		maxPages    = fs.Int("max-pages", 100000, "")
+	)
+	fs.Usage = func() {}
+
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return errors.New(exportUsage)
+	}
+
+	dir, err := filepath.Abs(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if err := config.Setup(); err != nil {
+		return err
+	}
+
+	if err := datax.SearchIndexOffline(); err != nil {
+		return err
+	}
+
+	server, err := exportServerStart()
+	if err != nil {
+		return err
+	}
+
+	it := &exporter{
+		dir:         dir,
+		server:      server,
+		incremental: *incremental,
+		maxPages:    *maxPages,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		queued: map[string]bool{},
+		seeds:  map[string]bool{},
+	}
+
+	return it.run()
+}
+
+// exportServerStart starts the frontend modules on a free local port
+func exportServerStart() (string, error) {
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	lis.Close()
+
+	serviceSetup()
+	httpsrv.GlobalService.Config.HttpPort = uint16(port)
+
+	httpsrv.GlobalService.ModuleRegister("/hp/-", cmod.NewModule())
+	httpsrv.GlobalService.ModuleRegister("/hp", cdef.NewHtpModule())
+	httpsrv.GlobalService.ModuleRegister("/", cdef.NewModule())
+
+	if err := httpsrv.GlobalService.Start(); err != nil {
+		return "", err
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			conn.Close()
+			return "http://" + addr, nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return "", errors.New("export server start timeout")
+}
+
+func (it *exporter) run() error {
+
+	if err := os.MkdirAll(it.dir, 0755); err != nil {
+		return err
+	}
+
+	tn := time.Now().Unix()
+
+	manifestFile := filepath.Join(it.dir, exportManifestFile)
+	if it.incremental {
+		if err := json.DecodeFile(manifestFile, &it.manifest); err != nil ||
+			it.manifest.Version != config.SysVersionSign || it.manifest.Nodes == nil {
+			fmt.Println("no compatible previous export found, run a full export")
+			it.incremental = false
+		}
+	}
+
+	if !it.incremental || it.manifest.Pages == nil {
+		it.manifest = exportManifest{
+			Pages: map[string]string{},
+			Nodes: map[string]string{},
+		}
+	}
+
+	if err := it.seedsInit(); err != nil {
+		return err
+	}
+
+	for len(it.queue) > 0 && it.pages < it.maxPages {
+
+		u := it.queue[0]
+		it.queue = it.queue[1:]
+
+		if err := it.fetch(u); err != nil {
+			fmt.Printf("  error %s : %s\n", u, err.Error())
+		}
+	}
+
+	it.manifest.Version = config.SysVersionSign
+	it.manifest.Exported = tn
+
+	if err := json.EncodeToFile(it.manifest, manifestFile, "  "); err != nil {
+		return err
+	}
+
+	fmt.Printf("export to %s, pages %d, assets %d, removed %d\n",
+		it.dir, it.pages, it.assets, it.removed)
+
+	return nil
+}
+
+// seedsInit queues the routes without params, the permalinks of the nodes
+// and the term pages of the node lists of every enabled module. In the
+// incremental mode only the modules with changed or removed nodes are
+// queued, with the list, term and pager pages of the previous export, and
+// the pages of the removed or unpublished nodes are deleted.
+func (it *exporter) seedsInit() error {
+
+	mods := []*api.Spec{}
+	for _, mod := range config.Modules {
+		if mod.Status == 1 && mod.Meta.Name != "core/comment" {
+			mods = append(mods, mod)
+		}
+	}
+	sort.Slice(mods, func(i, j int) bool {
+		return mods[i].SrvName < mods[j].SrvName
+	})
+
+	if !it.incremental {
+		it.seed("/")
+	}
+
+	nodes := map[string]string{}
+
+	for _, mod := range mods {
+
+		changed := !it.incremental
+
+		for _, model := range mod.NodeModels {
+
+			for offset := int64(0); ; offset += 100 {
+
+				qry := datax.NewQuery(mod.Meta.Name, model.Meta.Name)
+				qry.Limit(100)
+				qry.Offset(offset)
+				qry.Order("created asc")
+				qry.Filter("status", 1)
+
+				ls := qry.NodeList([]string{}, []string{})
+				if ls.Error != nil {
+					return errors.New(ls.Error.Message)
+				}
+
+				for _, node := range ls.Items {
+
+					u := datax.NodeEntryPermalink(mod, model, &node)
+					if u == "" {
+						continue
+					}
+					u = config.HttpSrvBasePath(u)
+
+					if file := it.urlFile(u); file != "" {
+						nodes[file] = mod.Meta.Name
+					}
+
+					if !it.incremental || int64(node.Updated) >= it.manifest.Exported {
+						changed = true
+						it.seed(u)
+					}
+				}
+
+				if len(ls.Items) < 100 {
+					break
+				}
+			}
+		}
+
+		if it.incremental {
+			for file, modname := range it.manifest.Nodes {
+				if modname == mod.Meta.Name && nodes[file] == "" {
+					it.remove(file)
+					changed = true
+				}
+			}
+		}
+
+		if !changed {
+			continue
+		}
+
+		it.seed(config.HttpSrvBasePath(mod.SrvName))
+
+		for i := range mod.Router.Routes {
+
+			route := &mod.Router.Routes[i]
+
+			path, ok := route.Build(nil)
+			if !ok {
+				continue
+			}
+
+			routePath := config.HttpSrvBasePath(mod.SrvName + path)
+			it.seed(routePath)
+			it.seedTerms(mod, route.DataAction, routePath)
+		}
+
+		// the pager and other list pages which were linked from the pages
+		if it.incremental {
+			modPath := config.HttpSrvBasePath(mod.SrvName)
+			for file, u := range it.manifest.Pages {
+				if _, ok := it.manifest.Nodes[file]; ok {
+					continue
+				}
+				if ru, err := url.Parse(u); err == nil &&
+					(ru.Path == modPath || strings.HasPrefix(ru.Path, modPath+"/")) {
+					it.seed(u)
+				}
+			}
+		}
+	}
+
+	it.manifest.Nodes = nodes
+
+	return nil
+}
+
+func (it *exporter) seedTerms(mod *api.Spec, actionName, routePath string) {
+
+	for _, action := range mod.Actions {
+
+		if action.Name != actionName {
+			continue
+		}
+
+		for _, ad := range action.Datax {
+
+			if ad.Type != "node.list" {
+				continue
+			}
+
+			model, err := config.SpecNodeModel(mod.Meta.Name, ad.Query.Table)
+			if err != nil {
+				continue
+			}
+
+			for _, term := range model.Terms {
+
+				if term.Type != api.TermTaxonomy {
+					continue
+				}
+
+				qry := datax.NewQuery(mod.Meta.Name, term.Meta.Name)
+				qry.Limit(1000)
+				ls := qry.TermList()
+				for _, v := range ls.Items {
+					it.seed(fmt.Sprintf("%s?term_%s=%d", routePath, term.Meta.Name, v.ID))
+				}
+			}
+		}
+	}
+}
+
+// urlFile returns the exported file path of the url, or an empty string
+func (it *exporter) urlFile(u string) string {
+	if ru, err := url.Parse(u); err == nil {
+		if file, ok := it.localFile(ru); ok {
+			return file
+		}
+	}
+	return ""
+}
+
+// remove deletes the exported page of a removed node
+func (it *exporter) remove(file string) {
+	if _, ok := it.manifest.Pages[file]; ok {
+		os.Remove(filepath.Join(it.dir, filepath.FromSlash(file)))
+		delete(it.manifest.Pages, file)
+		it.removed++
+	}
+}
+
+func (it *exporter) seed(u string) {
+	if file := it.enqueue(u); file != "" {
+		it.seeds[file] = true
+	}
+}
+
+// enqueue queues the url once per exported file, it returns the file path
+func (it *exporter) enqueue(u string) string {
+
+	ru, err := url.Parse(u)
+	if err != nil {
+		return ""
+	}
+
+	file, ok := it.localFile(ru)
+	if !ok {
+		return ""
+	}
+
+	if !it.queued[file] {
+		it.queued[file] = true
+		it.queue = append(it.queue, u)
+	}
+
+	return file
+}
+
+// localFile returns the exported file path of a frontend url, the second
+// value is false if the url is not exportable.
+func (it *exporter) localFile(u *url.URL) (string, bool) {
+
+	if (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") ||
+		(u.Host != "" && "http://"+u.Host != it.server) {
+		return "", false
+	}
+
+	p := path.Clean("/" + u.Path)
+
+	basePath := config.HttpSrvBasePath("")
+	if basePath != "/" {
+		if p != basePath && !strings.HasPrefix(p, basePath+"/") {
+			return "", false
+		}
+		p = path.Clean("/" + p[len(basePath):])
+	}
+
+	for _, v := range exportAssetPaths {
+		if strings.HasPrefix(p, v) {
+			return p[1:], true
+		}
+	}
+
+	if strings.HasPrefix(p, "/hp/") {
+		return "", false
+	}
+
+	dir := strings.Trim(p, "/")
+	if dir != "" {
+		if _, ok := config.Modules[strings.Split(dir, "/")[0]]; !ok {
+			return "", false
+		}
+	}
+
+	query := u.Query()
+	if len(query) == 0 && strings.HasSuffix(dir, ".html") {
+		return dir, true
+	}
+
+	if len(query) > 0 {
+
+		args := []string{}
+		for k, vs := range query {
+			if k != "page" && !strings.HasPrefix(k, "term_") {
+				return "", false
+			}
+			if len(vs) < 1 {
+				continue
+			}
+			v := vs[0]
+			if !exportQueryValReg.MatchString(v) {
+				h := md5.Sum([]byte(v))
+				v = hex.EncodeToString(h[:])[:12]
+			}
+			args = append(args, k+"-"+v)
+		}
+		sort.Strings(args)
+
+		dir = path.Join(dir, "_"+strings.Join(args, "_"))
+	}
+
+	return path.Join(dir, "index.html"), true
+}
+
+func (it *exporter) fetch(u string) error {
+
+	ru, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+
+	file, ok := it.localFile(ru)
+	if !ok {
+		return nil
+	}
+
+	if it.incremental && !it.seeds[file] {
+		if _, ok := it.manifest.Pages[file]; ok {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(it.dir, file)); err == nil {
+			return nil
+		}
+	}
+
+	rsp, err := it.client.Get(it.server + u)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+
+	var (
+		ctype  = rsp.Header.Get("Content-Type")
+		isPage = strings.HasPrefix(ctype, "text/html")
+	)
+
+	switch {
+
+	case rsp.StatusCode == http.StatusNotFound:
+		// unpublished or removed
+		it.remove(file)
+		return nil
+
+	case rsp.StatusCode >= 300 && rsp.StatusCode < 400:
+		loc, err := ru.Parse(rsp.Header.Get("Location"))
+		if err != nil {
+			return err
+		}
+		target := it.linkRewrite(file, ru, loc.String())
+		body = []byte(fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8">`+
+			`<meta http-equiv="refresh" content="0; url=%s"><link rel="canonical" href="%s">`+
+			`</head><body></body></html>`, target, target))
+		isPage = true
+
+	case rsp.StatusCode != http.StatusOK:
+		return fmt.Errorf("http status %d", rsp.StatusCode)
+
+	case isPage:
+		body = exportLinkReg.ReplaceAllFunc(body, func(b []byte) []byte {
+			m := exportLinkReg.FindSubmatch(b)
+			quote := m[2][:1]
+			link := it.linkRewrite(file, ru, string(m[2][1:len(m[2])-1]))
+			return []byte(string(m[1]) + "=" + string(quote) + link + string(quote))
+		})
+
+	case strings.HasPrefix(ctype, "text/css"):
+		body = exportCssUrlReg.ReplaceAllFunc(body, func(b []byte) []byte {
+			m := exportCssUrlReg.FindSubmatch(b)
+			link := strings.Trim(string(m[1]), `"'`)
+			return []byte("url(\"" + it.linkRewrite(file, ru, link) + "\")")
+		})
+	}
+
+	dst := filepath.Join(it.dir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	if prev, err := ioutil.ReadFile(dst); err != nil || !bytes.Equal(prev, body) {
+		if err := ioutil.WriteFile(dst, body, 0644); err != nil {
+			return err
+		}
+	}
+
+	if isPage {
+		it.pages++
+		it.manifest.Pages[file] = u
+	} else {
+		it.assets++
+	}
+
+	return nil
+}
+
+// linkRewrite queues the exportable link and returns its path relative to
+// the file which refers to it, other links are returned unchanged.
+func (it *exporter) linkRewrite(file string, base *url.URL, link string) string {
+
+	if link == "" || link[0] == '#' || strings.HasPrefix(link, "data:") ||
+		strings.HasPrefix(link, "javascript:") || strings.HasPrefix(link, "mailto:") ||
+		strings.Contains(link, "{[") {
+		return link
+	}
+
+	lu, err := base.Parse(link)
+	if err != nil {
+		return link
+	}
+
+	target, ok := it.localFile(lu)
+	if !ok {
+		return link
+	}
+
+	fragment := lu.Fragment
+	lu.Fragment = ""
+	lu.Scheme, lu.Host = "", ""
+	if strings.HasPrefix(target, "hp/") {
+		lu.RawQuery = ""
+	}
+	it.enqueue(lu.String())
+
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(file)), filepath.FromSlash(target))
+	if err != nil {
+		return link
+	}
+	rel = filepath.ToSlash(rel)
+
+	if fragment != "" {
+		rel += "#" + fragment
+	}
+
+	return rel
+}
@@ -62,6 +62,22 @@ func main() {
 		return
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := exportCommand(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "search-index" {
+		if err := searchIndexCommand(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	//
 	retry := time.Second * 3
 	for i := 0; ; i++ {
@@ -92,19 +108,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	iamclient.ServiceUrl = config.Config.IamServiceUrl
-	iamclient.ServiceUrlFrontend = config.Config.IamServiceUrlFrontend
-
-	iamclient.InstanceID = config.Config.InstanceID
-	iamclient.InstanceOwner = config.Config.AppInstance.Meta.User
-
-	httpsrv.GlobalService.Config.UrlBasePath = config.Config.UrlBasePath
-	httpsrv.GlobalService.Config.HttpPort = config.Config.HttpPort
-
-	// i18n
-	hlang.StdLangFeed.LoadMessages(config.Prefix+"/i18n/en.json", true)
-	hlang.StdLangFeed.LoadMessages(config.Prefix+"/i18n/zh-CN.json", true)
-	hlang.StdLangFeed.Init()
+	serviceSetup()
 
 	// status
 	status.Init()
@@ -130,3 +134,20 @@ func main() {
 
 	select {}
 }
+
+func serviceSetup() {
+
+	iamclient.ServiceUrl = config.Config.IamServiceUrl
+	iamclient.ServiceUrlFrontend = config.Config.IamServiceUrlFrontend
+
+	iamclient.InstanceID = config.Config.InstanceID
+	iamclient.InstanceOwner = config.Config.AppInstance.Meta.User
+
+	httpsrv.GlobalService.Config.UrlBasePath = config.Config.UrlBasePath
+	httpsrv.GlobalService.Config.HttpPort = config.Config.HttpPort
+
+	// i18n
+	hlang.StdLangFeed.LoadMessages(config.Prefix+"/i18n/en.json", true)
+	hlang.StdLangFeed.LoadMessages(config.Prefix+"/i18n/zh-CN.json", true)
+	hlang.StdLangFeed.Init()
+}
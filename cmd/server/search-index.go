@@ -0,0 +1,115 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+const searchIndexUsage = `usage: server search-index <command> [bucket]
+
+commands:
+  status           show the index state of all buckets
+  reindex <bucket> force a full reindex of the bucket
+  check <bucket>   compare the indexed documents with the published rows
+  repair <bucket>  run the check and repair the differences
+
+the server should be stopped before running the commands. check and repair
+need a running searchd with the sphinxsearch engine, use the search-index
+check API of the running server instead.`
+
+func searchIndexCommand(args []string) error {
+
+	if len(args) < 1 {
+		return errors.New(searchIndexUsage)
+	}
+
+	if err := config.Setup(); err != nil {
+		return err
+	}
+
+	if err := datax.SearchIndexOffline(); err != nil {
+		return err
+	}
+
+	switch args[0] {
+
+	case "status":
+		ls := datax.SearchIndexStatusList()
+		if ls.Error != nil {
+			return errors.New(ls.Error.Message)
+		}
+		for _, v := range ls.Items {
+			fmt.Printf("%s  %s/%s  engine %s, documents %d, full %s, delta %s, pending %d, sync %s\n",
+				v.Bucket, v.ModName, v.Model, v.Engine, v.Documents,
+				searchIndexTime(v.FullIndexed), searchIndexTime(v.DeltaIndexed),
+				v.DeltaPending, searchIndexTime(v.SyncUpdated))
+			if v.Error != "" {
+				fmt.Printf("  error %s\n", v.Error)
+			}
+		}
+		return nil
+
+	case "reindex":
+		if len(args) < 2 {
+			return errors.New(searchIndexUsage)
+		}
+		if err := datax.SearchIndexReindex(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("%s  reindex scheduled\n", args[1])
+		return nil
+
+	case "check", "repair":
+		if len(args) < 2 {
+			return errors.New(searchIndexUsage)
+		}
+		rsp := datax.SearchIndexCheck(args[1], args[0] == "repair")
+		if rsp.Error != nil {
+			return errors.New(rsp.Error.Message)
+		}
+		searchIndexCheckPrint(&rsp)
+		return nil
+	}
+
+	return errors.New(searchIndexUsage)
+}
+
+func searchIndexCheckPrint(rsp *api.NodeSearchIndexCheck) {
+
+	fmt.Printf("%s  rows %d, documents %d, missing %d, stale %d, repaired %d\n",
+		rsp.Bucket, rsp.Rows, rsp.Documents, len(rsp.Missing), len(rsp.Stale), rsp.Repaired)
+
+	for _, id := range rsp.Missing {
+		fmt.Printf("  missing %s\n", id)
+	}
+
+	for _, id := range rsp.Stale {
+		fmt.Printf("  stale %s\n", id)
+	}
+}
+
+func searchIndexTime(tn int64) string {
+	if tn < 1 {
+		return "-"
+	}
+	return time.Unix(tn, 0).Format("2006-01-02 15:04:05")
+}
@@ -77,6 +77,8 @@ type ConfigCommon struct {
 	ExtUpDatabases        connect.MultiConnOptions `json:"ext_up_databases,omitempty" toml:"ext_up_databases,omitempty"`
 	ExpModuleInits        []string                 `json:"exp_module_inits,omitempty" toml:"exp_module_inits,omitempty"`
 	ExpGdocPaths          []string                 `json:"exp_gdoc_paths,omitempty" toml:"exp_gdoc_paths,omitempty"`
+	SearchEngine          string                   `json:"search_engine,omitempty" toml:"search_engine,omitempty"`
+	SearchTokenizer       string                   `json:"search_tokenizer,omitempty" toml:"search_tokenizer,omitempty"`
 }
 
 func init() {
@@ -131,6 +133,43 @@ func init() {
 		"Multi languages support list", "",
 	})
 
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_search_excerpt_length", "200",
+		"Max length of the search result excerpts", "",
+	})
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_search_excerpt_before_match", "<b>",
+		"HTML inserted before the matched words in search result excerpts", "",
+	})
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_search_excerpt_after_match", "</b>",
+		"HTML inserted after the matched words in search result excerpts", "",
+	})
+
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_search_module_weights", "",
+		"Per-module ranking weights of the global search, e.g. core/blog=1.0,core/gdoc=1.5", "",
+	})
+
+	SysConfigList.Insert(api.SysConfig{
+		"search_log_retention_days", "30",
+		"Days to keep the search query logs, 0 to disable the logging", "",
+	})
+
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_page_cache_ttl", "0",
+		"Seconds to cache the rendered pages of anonymous visitors, 0 to disable the cache", "",
+	})
+
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_robots_txt", "User-agent: *\nDisallow: /hp/v1/\n",
+		"The robots.txt policy, the Sitemap line is appended automatically", "text",
+	})
+	SysConfigList.Insert(api.SysConfig{
+		"frontend_robots_staging", "0",
+		"Set 1 for a staging site, the crawlers are disallowed on all pages", "",
+	})
+
 	SysConfigList.Insert(api.SysConfig{
 		"storage_service_endpoint", "/hp/s2/deft",
 		"Storage Service Endpoint", "",
@@ -18,6 +18,11 @@ import (
 	"github.com/hooto/iam/iamapi"
 )
 
+const (
+	SearchEngineSphinx = "sphinx"
+	SearchEngineLocal  = "local"
+)
+
 var (
 	StorageServiceEndpoint = "/hp/s2/deft"
 	coreModules            = []string{
@@ -17,7 +17,6 @@ package config
 import (
 	"errors"
 	"fmt"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -359,9 +358,19 @@ func SpecSrvRefresh(srvname string) {
 		mtmu.Unlock()
 	}
 
-	for i, v := range spec.Router.Routes {
-		spec.Router.Routes[i].Tree = strings.Split(strings.Trim(filepath.Clean(v.Path), "/"), "/")
+	// the routes are served concurrently, compile and sort a copy then swap it in
+	routes := make([]api.Route, len(spec.Router.Routes))
+	copy(routes, spec.Router.Routes)
+	for i, v := range routes {
+		if err := api.RouteCompile(&routes[i]); err != nil {
+			hlog.Printf("warn", "spec %s route %s : %s", spec.Meta.Name, v.Path, err.Error())
+		}
 	}
+	api.RoutesSort(routes)
+
+	locker.Lock()
+	spec.Router.Routes = routes
+	locker.Unlock()
 
 	httpsrv.GlobalService.TemplateLoader.Clean(spec.Meta.Name)
 	httpsrv.GlobalService.TemplateLoader.Set(spec.Meta.Name,
@@ -434,6 +443,13 @@ func _instance_schema_sync(spec *api.Spec) error {
 			})
 		}
 
+		if nodeModel.Extensions.Seo {
+			tbl.AddColumn(&modeler.Column{
+				Name: "ext_seo",
+				Type: "string-text",
+			})
+		}
+
 		for _, field := range nodeModel.Fields {
 
 			switch field.Type {
@@ -0,0 +1,219 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/hooto/hlang4g/hlang"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+)
+
+const breadcrumbTermDepthMax = 10
+
+// Breadcrumb is an item of the trail returned by the template function
+// Breadcrumbs, the last item is the current page.
+type Breadcrumb struct {
+	Label  string
+	Href   string
+	Active bool
+	path   string // the request path without the base path
+}
+
+// Breadcrumbs builds the trail of the current page from the module title,
+// the taxonomy ancestors of the listed term or of the node, and the node
+// entries of the data action, in which a referred node is the parent of
+// the next one (e.g. doc > page).
+//
+//	{{range $v := Breadcrumbs $}}
+//	<li class="breadcrumb-item{{if $v.Active}} active{{end}}"><a href="{{$v.Href}}">{{$v.Label}}</a></li>
+//	{{end}}
+func Breadcrumbs(data map[string]interface{}) []*Breadcrumb {
+
+	var (
+		srvname, _    = data["srvname"].(string)
+		lang, _       = data["LANG"].(string)
+		actionName, _ = data["__datax_action__"].(string)
+	)
+
+	mod, ok := config.Modules[srvname]
+	if !ok {
+		return nil
+	}
+
+	ls := []*Breadcrumb{
+		{
+			Label: hlang.StdLangFeed.Translate(lang, mod.Title),
+			path:  "/" + mod.SrvName,
+		},
+	}
+
+	var (
+		prev     *api.Node
+		taxonomy = false
+	)
+
+	for _, action := range mod.Actions {
+
+		if action.Name != actionName {
+			continue
+		}
+
+		for _, ad := range action.Datax {
+
+			switch ad.Type {
+
+			case "node.entry":
+				node, ok := data[ad.Name].(api.Node)
+				if !ok || node.ID == "" || node.Model == nil {
+					continue
+				}
+
+				if !taxonomy {
+					for _, term := range node.Terms {
+						if term.Type == api.TermTaxonomy && term.Value != "" && term.Value != "0" {
+							ls = append(ls, breadcrumbTermTrail(mod, term.Name, term.Value)...)
+							taxonomy = true
+							break
+						}
+					}
+				}
+
+				var path string
+				if prev != nil && node.Model.Extensions.NodeRefer == prev.Model.Meta.Name {
+					path = NodeReferPermalink(mod, node.Model.Meta.Name, &node, prev.Model.Meta.Name, prev)
+				} else {
+					path = NodePermalink(mod, node.Model.Meta.Name, &node)
+				}
+
+				label := TextHtml2Str(FieldStringPrint(node, "title", lang))
+				if label == "" {
+					label = node.Title
+				}
+
+				ls = append(ls, &Breadcrumb{
+					Label: label,
+					path:  path,
+				})
+				prev = &node
+
+			case "node.list":
+				if taxonomy {
+					continue
+				}
+
+				model, err := config.SpecNodeModel(mod.Meta.Name, ad.Query.Table)
+				if err != nil {
+					continue
+				}
+
+				for _, term := range model.Terms {
+					if term.Type != api.TermTaxonomy {
+						continue
+					}
+					if v, ok := data["term_"+term.Meta.Name].(string); ok && v != "" {
+						ls = append(ls, breadcrumbTermTrail(mod, term.Meta.Name, v)...)
+						taxonomy = true
+						break
+					}
+				}
+			}
+		}
+
+		break
+	}
+
+	for _, v := range ls {
+		if v.path != "" {
+			v.Href = config.HttpSrvBasePath(v.path)
+		}
+	}
+	ls[len(ls)-1].Active = true
+
+	return ls
+}
+
+// breadcrumbTermTrail returns the term and its ancestors from the root
+func breadcrumbTermTrail(mod *api.Spec, termName, termid string) []*Breadcrumb {
+
+	// loads the taxonomy cache of the term model
+	if len(TermTaxonomyCacheIndexes(mod.Meta.Name, termName, termid)) == 0 {
+		return nil
+	}
+
+	var (
+		ls     = []*Breadcrumb{}
+		path   = termListPath(mod, termName)
+		tid, _ = strconv.ParseUint(termid, 10, 32)
+		id     = uint32(tid)
+	)
+
+	for i := 0; i < breadcrumbTermDepthMax && id > 0; i++ {
+
+		term := TermTaxonomyCacheEntry(mod.Meta.Name, termName, id)
+		if term == nil {
+			break
+		}
+
+		v := &Breadcrumb{
+			Label: term.Title,
+		}
+		if path != "" {
+			v.path = path + "?term_" + termName + "=" + strconv.FormatUint(uint64(term.ID), 10)
+		}
+
+		ls = append([]*Breadcrumb{v}, ls...)
+		id = term.PID
+	}
+
+	return ls
+}
+
+// BreadcrumbsRender renders the trail of Breadcrumbs as an ordered list
+//
+//	{{BreadcrumbsRender $}}
+func BreadcrumbsRender(data map[string]interface{}) template.HTML {
+
+	ls := Breadcrumbs(data)
+	if len(ls) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<nav class="breadcrumb hp-breadcrumb" aria-label="breadcrumb"><ol class="breadcrumb">`)
+
+	for _, v := range ls {
+		if v.Active {
+			sb.WriteString(`<li class="breadcrumb-item active is-active" aria-current="page">`)
+		} else {
+			sb.WriteString(`<li class="breadcrumb-item">`)
+		}
+		if v.Href != "" {
+			sb.WriteString(`<a href="` + template.HTMLEscapeString(v.Href) + `">` +
+				template.HTMLEscapeString(v.Label) + "</a>")
+		} else {
+			sb.WriteString("<span>" + template.HTMLEscapeString(v.Label) + "</span>")
+		}
+		sb.WriteString("</li>")
+	}
+
+	sb.WriteString("</ol></nav>")
+
+	return template.HTML(sb.String())
+}
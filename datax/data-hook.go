@@ -0,0 +1,72 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/lessos/lessgo/utils"
+
+	"github.com/hooto/hpress/config"
+)
+
+// NodeChanged is the write hook of the node tables, every writer calls it
+// after a node is created, updated or deleted, e.g. the v1 api, the gdoc
+// sync and the data sync, to purge the caches which are built from the node.
+func NodeChanged(modname, model, id string) {
+	PageCachePurgeNode(modname, model, id)
+	SitemapPurge(modname)
+	MenuPurge()
+}
+
+// TermChanged is the write hook of the term tables, same as NodeChanged.
+func TermChanged(modname, model string, id uint32) {
+	PageCachePurgeTerm(modname, model, id)
+	SitemapPurge(modname)
+	MenuPurge()
+}
+
+// dataTableChanged calls the write hook of a row of the node (hpn_) or
+// term (hpt_) tables whose module is found by the table name.
+func dataTableChanged(table, id string) {
+
+	if len(table) < 4 {
+		return
+	}
+
+	for _, mod := range config.Modules {
+
+		prefix := table[:4] + utils.StringEncode16(mod.Meta.Name, 12) + "_"
+		if !strings.HasPrefix(table, prefix) {
+			continue
+		}
+
+		model := table[len(prefix):]
+
+		switch table[:4] {
+
+		case "hpn_":
+			NodeChanged(mod.Meta.Name, model, id)
+
+		case "hpt_":
+			if tid, err := strconv.ParseUint(id, 10, 32); err == nil {
+				TermChanged(mod.Meta.Name, model, uint32(tid))
+			}
+		}
+
+		break
+	}
+}
@@ -374,9 +374,9 @@ func gdocRefreshItem(docId, userId, ver, dir string) error {
 				fr := store.Data.NewFilter().And("id", nodeId)
 				_, err = store.Data.Update(table, sets, fr)
 			} else {
-				err = nil
 				hlog.Printf("debug", "doc %s, page %s, path %s, skip",
 					docId, nodeId, subPath)
+				continue
 			}
 		}
 
@@ -384,6 +384,7 @@ func gdocRefreshItem(docId, userId, ver, dir string) error {
 			hlog.Printf("info", "doc %s, page %s, path %s, refreshed err %s",
 				docId, nodeId, subPath, err.Error())
 		} else {
+			NodeChanged("core/gdoc", "page", nodeId)
 			hlog.Printf("debug", "doc %s, page %s, path %s, refreshed %d",
 				docId, nodeId, subPath, len(bs))
 		}
@@ -430,5 +431,7 @@ func gdocRefreshItem(docId, userId, ver, dir string) error {
 		return err
 	}
 
+	NodeChanged("core/gdoc", "doc", docId)
+
 	return nil
 }
@@ -207,6 +207,7 @@ func data_sync_pull() error {
 						} else {
 							// fmt.Println("  OK INSERT", vt.Name, v.Field("id").String())
 							cnew += 1
+							dataTableChanged(vt.Name, v.Field("id").String())
 						}
 
 					} else if err != nil {
@@ -257,6 +258,7 @@ func data_sync_pull() error {
 							} else {
 								// fmt.Println("  OK UPDATE", vt.Name, v.Field("id").String())
 								cupd += 1
+								dataTableChanged(vt.Name, v.Field("id").String())
 							}
 						} else {
 							// fmt.Println("  OK IGNORE ", vt.Name, v.Field("id").String())
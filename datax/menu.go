@@ -0,0 +1,431 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"errors"
+	"html/template"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+// menuLink is the resolved link of a menu item, the items whose target
+// is not found or not published are dropped.
+type menuLink struct {
+	item     *api.MenuItem
+	path     string // the request path, or the external url
+	external bool
+	termKey  string
+	termVal  string
+	items    []*menuLink
+}
+
+// MenuLink is a menu item of the current page, returned by the
+// template function Menu.
+type MenuLink struct {
+	Label  string
+	Href   string
+	Active bool // links to the current page
+	Trail  bool // the current page is below it
+	Items  []*MenuLink
+}
+
+var (
+	menuMu     sync.RWMutex
+	menuCaches = map[string][]*menuLink{}
+)
+
+func MenuList() api.MenuList {
+
+	var (
+		ls     api.MenuList
+		prefix = api.NsMenu("")
+		offset = prefix
+	)
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, prefix).
+			LimitNumSet(100).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			var entry api.Menu
+			if err := v.Decode(&entry); err == nil {
+				ls.Items = append(ls.Items, &entry)
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	ls.Kind = "MenuList"
+
+	return ls
+}
+
+func MenuEntry(name string) *api.Menu {
+
+	var entry api.Menu
+	if rs := store.DataLocal.NewReader(api.NsMenu(name)).Query(); rs.OK() {
+		if err := rs.Decode(&entry); err == nil {
+			return &entry
+		}
+	}
+
+	return nil
+}
+
+func MenuSet(entry *api.Menu) error {
+
+	if err := entry.Valid(); err != nil {
+		return err
+	}
+
+	if prev := MenuEntry(entry.Name); prev != nil {
+		entry.Created = prev.Created
+	}
+
+	tn := time.Now().Unix()
+	if entry.Created == 0 {
+		entry.Created = tn
+	}
+	entry.Updated = tn
+
+	if rs := store.DataLocal.NewWriter(api.NsMenu(entry.Name), entry).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Put Error")
+	}
+
+	MenuPurge()
+	PageCachePurge(PageCacheTagMenu(entry.Name), PageCacheTagPagelets())
+
+	return nil
+}
+
+func MenuDel(name string) error {
+
+	if !api.SiteNameReg.MatchString(name) {
+		return errors.New("Invalid Menu Name")
+	}
+
+	if rs := store.DataLocal.NewWriter(api.NsMenu(name), nil).ModeDeleteSet(true).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Delete Error")
+	}
+
+	MenuPurge()
+	PageCachePurge(PageCacheTagMenu(name), PageCacheTagPagelets())
+
+	return nil
+}
+
+// MenuPurge drops the resolved links of all menus, it is called when
+// the nodes or terms are changed. The cached pages which render a menu
+// are purged if the links of the menu are changed, e.g. a node of the
+// menu is unpublished or its permalink is renamed.
+func MenuPurge() {
+
+	menuMu.Lock()
+	prev := menuCaches
+	menuCaches = map[string][]*menuLink{}
+	menuMu.Unlock()
+
+	for _, entry := range MenuList().Items {
+		if links, ok := prev[entry.Name]; !ok || !menuLinksEqual(links, menuLinks(entry.Name)) {
+			PageCachePurge(PageCacheTagMenu(entry.Name), PageCacheTagPagelets())
+		}
+	}
+}
+
+func menuLinksEqual(a, b []*menuLink) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i, v := range a {
+		if v.path != b[i].path || v.external != b[i].external ||
+			v.termKey != b[i].termKey || v.termVal != b[i].termVal ||
+			!menuLinksEqual(v.items, b[i].items) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func menuLinks(name string) []*menuLink {
+
+	menuMu.RLock()
+	links, ok := menuCaches[name]
+	menuMu.RUnlock()
+
+	if ok {
+		return links
+	}
+
+	if entry := MenuEntry(name); entry != nil {
+		links = menuLinksResolve(entry.Items)
+	}
+
+	menuMu.Lock()
+	menuCaches[name] = links
+	menuMu.Unlock()
+
+	return links
+}
+
+func menuLinksResolve(items []*api.MenuItem) []*menuLink {
+
+	links := []*menuLink{}
+
+	for _, item := range items {
+
+		link := &menuLink{
+			item: item,
+		}
+
+		switch item.Type {
+
+		case api.MenuItemUrl:
+			if u, err := url.Parse(item.Url); err == nil && u.Host != "" {
+				link.path, link.external = item.Url, true
+			} else {
+				link.path = item.Url
+			}
+
+		case api.MenuItemNode:
+			link.path = menuNodePath(item)
+
+		case api.MenuItemTerm:
+			link.path, link.termKey = menuTermPath(item), "term_"+item.Model
+			link.termVal = item.ID
+
+		case api.MenuItemRoute:
+			if mod := config.SpecGet(item.ModName); mod != nil && mod.Status == 1 {
+				link.path = "/" + mod.SrvName
+				if item.Path != "" {
+					link.path += "/" + item.Path
+				}
+			}
+		}
+
+		if link.path == "" {
+			continue
+		}
+
+		link.items = menuLinksResolve(item.Items)
+		links = append(links, link)
+	}
+
+	return links
+}
+
+func menuNodePath(item *api.MenuItem) string {
+
+	mod := config.SpecGet(item.ModName)
+	if mod == nil || mod.Status != 1 {
+		return ""
+	}
+
+	model, err := config.SpecNodeModel(mod.Meta.Name, item.Model)
+	if err != nil {
+		return ""
+	}
+
+	q := NewQuery(mod.Meta.Name, model.Meta.Name)
+	q.Filter("status", 1)
+	q.Filter("id", item.ID)
+
+	node := q.NodeEntry()
+	if node.ID == "" {
+		return ""
+	}
+
+	return NodeEntryPermalink(mod, model, &node)
+}
+
+func menuTermPath(item *api.MenuItem) string {
+	if mod := config.SpecGet(item.ModName); mod != nil && mod.Status == 1 {
+		return termListPath(mod, item.Model)
+	}
+	return ""
+}
+
+// termListPath returns the first node list route which can be filtered
+// by the term, e.g. /blog/list?term_categories=1
+func termListPath(mod *api.Spec, termName string) string {
+
+	for _, route := range mod.Router.Routes {
+
+		path := routeStaticPath(mod, &route)
+		if path == "" {
+			continue
+		}
+
+		for _, action := range mod.Actions {
+
+			if action.Name != route.DataAction {
+				continue
+			}
+
+			for _, ad := range action.Datax {
+
+				if ad.Type != "node.list" {
+					continue
+				}
+
+				model, err := config.SpecNodeModel(mod.Meta.Name, ad.Query.Table)
+				if err != nil {
+					continue
+				}
+
+				for _, term := range model.Terms {
+					if term.Meta.Name == termName {
+						return path
+					}
+				}
+			}
+
+			break
+		}
+	}
+
+	return ""
+}
+
+func menuLinksActive(links []*menuLink, data map[string]interface{}, lang, reqpath string) ([]*MenuLink, bool) {
+
+	var (
+		ls    = []*MenuLink{}
+		trail = false
+	)
+
+	for _, link := range links {
+
+		v := &MenuLink{
+			Label: link.item.Label(lang),
+			Href:  link.path,
+		}
+
+		if !link.external {
+
+			if link.termKey != "" {
+				v.Href += "?" + link.termKey + "=" + url.QueryEscape(link.termVal)
+				if link.path == reqpath {
+					v.Active = data[link.termKey] == link.termVal
+				}
+			} else {
+				v.Active = (link.path == reqpath)
+			}
+
+			if !v.Active && link.termKey == "" && link.path != "/" && link.path != "" &&
+				strings.HasPrefix(reqpath, strings.TrimSuffix(link.path, "/")+"/") {
+				v.Trail = true
+			}
+
+			if strings.HasPrefix(v.Href, "/") {
+				v.Href = config.HttpSrvBasePath(v.Href)
+				if strings.HasSuffix(link.path, "/") && !strings.HasSuffix(v.Href, "/") {
+					v.Href += "/"
+				}
+			}
+		}
+
+		if len(link.items) > 0 {
+			var sub bool
+			if v.Items, sub = menuLinksActive(link.items, data, lang, reqpath); sub {
+				v.Trail = true
+			}
+		}
+
+		if v.Active || v.Trail {
+			trail = true
+		}
+
+		ls = append(ls, v)
+	}
+
+	return ls, trail
+}
+
+// Menu returns the links of the named menu with the active trail of the
+// current http_request_path, the label is taken in the page language.
+//
+//	{{range $v := Menu $ "main"}}
+//	<li class="{{if $v.Active}}active{{else if $v.Trail}}active-trail{{end}}">
+//	  <a href="{{$v.Href}}">{{$v.Label}}</a>
+//	</li>
+//	{{end}}
+func Menu(data map[string]interface{}, name string) []*MenuLink {
+
+	var (
+		lang, _    = data["LANG"].(string)
+		reqpath, _ = data["http_request_path"].(string)
+	)
+
+	pageCacheDataTagsAdd(data, PageCacheTagMenu(name))
+
+	ls, _ := menuLinksActive(menuLinks(name), data, lang, reqpath)
+	return ls
+}
+
+// MenuRender renders the named menu as nested lists
+//
+//	{{MenuRender $ "footer"}}
+func MenuRender(data map[string]interface{}, name string) template.HTML {
+
+	ls := Menu(data, name)
+	if len(ls) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(`<ul class="hp-menu hp-menu-` + template.HTMLEscapeString(name) + `">`)
+	menuRenderItems(&sb, ls)
+	sb.WriteString("</ul>")
+
+	return template.HTML(sb.String())
+}
+
+func menuRenderItems(sb *strings.Builder, ls []*MenuLink) {
+
+	for _, v := range ls {
+
+		sb.WriteString(`<li class="hp-menu-item`)
+		if v.Active {
+			sb.WriteString(" active")
+		} else if v.Trail {
+			sb.WriteString(" active-trail")
+		}
+		sb.WriteString(`"><a href="` + template.HTMLEscapeString(v.Href) + `">` +
+			template.HTMLEscapeString(v.Label) + "</a>")
+
+		if len(v.Items) > 0 {
+			sb.WriteString(`<ul class="hp-menu-sub">`)
+			menuRenderItems(sb, v.Items)
+			sb.WriteString("</ul>")
+		}
+
+		sb.WriteString("</li>")
+	}
+}
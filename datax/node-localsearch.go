@@ -0,0 +1,495 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+// NodeLocalSearchEngine keeps an inverted index of nodes in the embedded
+// DataLocal store, it has no dependency on the external sphinx binaries.
+type NodeLocalSearchEngine struct {
+	mu     sync.RWMutex
+	prefix string
+	models map[string]*api.NodeModel
+}
+
+type localSearchDocument struct {
+	Status  int16               `json:"status"`
+	Created uint32              `json:"created"`
+	ModName string              `json:"modname,omitempty"`
+	Terms   []string            `json:"terms,omitempty"`
+	Attrs   map[string][]uint32 `json:"attrs,omitempty"`
+}
+
+type localSearchPosting struct {
+	Weight  int    `json:"weight"`
+	Created uint32 `json:"created"`
+}
+
+type localSearchHit struct {
+	id      string
+	weight  int
+	created uint32
+	matched int
+}
+
+const (
+	localSearchWeightTitle   = 40
+	localSearchWeightTags    = 20
+	localSearchWeightContent = 1
+	localSearchPostingMax    = 10000
+)
+
+func NewNodeLocalSearchEngine(prefix string) (NodeSearchEngine, error) {
+
+	if store.DataLocal == nil {
+		return nil, errors.New("No DataLocal Found")
+	}
+
+	engine := &NodeLocalSearchEngine{
+		prefix: prefix,
+		models: map[string]*api.NodeModel{},
+	}
+
+	return engine, nil
+}
+
+func (it *NodeLocalSearchEngine) ModelSet(bukname string, model *api.NodeModel) error {
+
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	it.models[bukname] = model
+
+	return nil
+}
+
+func (it *NodeLocalSearchEngine) Put(bukname string, node api.Node) error {
+
+	if rs := store.DataLocal.NewWriter(api.NsTextSearchCacheNodeEntry(bukname, node.ID), node).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Put Error")
+	}
+
+	var prev localSearchDocument
+	if rs := store.DataLocal.NewReader(api.NsTextSearchLocalDocument(bukname, node.ID)).Query(); rs.OK() {
+		rs.Decode(&prev)
+	}
+
+	for _, term := range prev.Terms {
+		store.DataLocal.NewWriter(api.NsTextSearchLocalTerm(bukname, term, node.ID), nil).
+			ModeDeleteSet(true).Commit()
+	}
+
+	if node.Status != 1 {
+		store.DataLocal.NewWriter(api.NsTextSearchLocalDocument(bukname, node.ID), nil).
+			ModeDeleteSet(true).Commit()
+		return nil
+	}
+
+	var (
+		doc = localSearchDocument{
+			Status:  node.Status,
+			Created: node.Created,
+			ModName: it.modName(bukname),
+			Attrs:   searchNodeTermIDs(&node),
+		}
+		weights = localSearchDocumentWeights(doc.ModName, &node)
+	)
+
+	for term, weight := range weights {
+
+		if rs := store.DataLocal.NewWriter(api.NsTextSearchLocalTerm(bukname, term, node.ID), localSearchPosting{
+			Weight:  weight,
+			Created: node.Created,
+		}).Commit(); !rs.OK() {
+			return errors.New("DataLocal/Put Error")
+		}
+
+		doc.Terms = append(doc.Terms, term)
+	}
+
+	sort.Strings(doc.Terms)
+
+	if rs := store.DataLocal.NewWriter(api.NsTextSearchLocalDocument(bukname, node.ID), doc).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Put Error")
+	}
+
+	return nil
+}
+
+func (it *NodeLocalSearchEngine) modName(bukname string) string {
+
+	it.mu.RLock()
+	defer it.mu.RUnlock()
+
+	if model, ok := it.models[bukname]; ok {
+		return model.ModName
+	}
+
+	return ""
+}
+
+func (it *NodeLocalSearchEngine) Keywords(bukname, q string) (map[string]int64, error) {
+
+	kws := map[string]int64{}
+
+	for _, term := range searchDictTokens(it.modName(bukname), "", searchTextTokens(q), false) {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(
+			api.NsTextSearchLocalTerm(bukname, term, ""),
+			api.NsTextSearchLocalTerm(bukname, term, "")).LimitNumSet(100).Query()
+
+		kws[term] = int64(len(rs.Items))
+	}
+
+	return kws, nil
+}
+
+func (it *NodeLocalSearchEngine) Status(bukname string) *api.NodeSearchIndexStatus {
+
+	status := &api.NodeSearchIndexStatus{
+		Bucket: bukname,
+		Engine: config.SearchEngineLocal,
+	}
+
+	if ids, err := it.Indexed(bukname); err == nil {
+		status.Documents = int64(len(ids))
+	}
+
+	return status
+}
+
+func (it *NodeLocalSearchEngine) Indexed(bukname string) (map[string]bool, error) {
+
+	var (
+		offset = api.NsTextSearchLocalDocument(bukname, "")
+		cutset = api.NsTextSearchLocalDocument(bukname, "")
+		ids    = map[string]bool{}
+	)
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+			LimitNumSet(1000).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			if n := bytes.LastIndexByte(v.Meta.Key, ':'); n >= 0 {
+				ids[string(v.Meta.Key[n+1:])] = true
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
+// Reindex drops the documents and postings of the bucket, and then rebuilds
+// them from the node caches.
+func (it *NodeLocalSearchEngine) Reindex(bukname string) error {
+
+	var (
+		offset = api.NsTextSearchLocalDocument(bukname, "")
+		cutset = api.NsTextSearchLocalDocument(bukname, "")
+	)
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+			LimitNumSet(1000).Query()
+
+		for _, v := range rs.Items {
+
+			offset = v.Meta.Key
+
+			var doc localSearchDocument
+			if err := v.Decode(&doc); err != nil {
+				continue
+			}
+
+			n := bytes.LastIndexByte(v.Meta.Key, ':')
+			if n < 0 {
+				continue
+			}
+
+			for _, term := range doc.Terms {
+				store.DataLocal.NewWriter(api.NsTextSearchLocalTerm(bukname, term, string(v.Meta.Key[n+1:])), nil).
+					ModeDeleteSet(true).Commit()
+			}
+
+			store.DataLocal.NewWriter(v.Meta.Key, nil).ModeDeleteSet(true).Commit()
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	offset = api.NsTextSearchCacheNodeEntry(bukname, "")
+	cutset = api.NsTextSearchCacheNodeEntry(bukname, "")
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+			LimitNumSet(1000).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			var node api.Node
+			if err := v.Decode(&node); err == nil {
+				if err := it.Put(bukname, node); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	return nil
+}
+
+func (it *NodeLocalSearchEngine) Query(bukname string, sq *NodeSearchQuery, qs *QuerySet) api.NodeList {
+
+	var (
+		ls    api.NodeList
+		terms = types.ArrayString{}
+		hits  = map[string]*localSearchHit{}
+	)
+
+	for _, term := range searchDictTokens(sq.ModName, sq.Lang, searchTextTokens(sq.Text), false) {
+		terms.Set(term)
+	}
+
+	for i, term := range terms {
+
+		var (
+			offset = api.NsTextSearchLocalTerm(bukname, term, "")
+			cutset = api.NsTextSearchLocalTerm(bukname, term, "")
+			num    = 0
+		)
+
+		for num < localSearchPostingMax {
+
+			rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+				LimitNumSet(1000).Query()
+
+			for _, v := range rs.Items {
+
+				offset = v.Meta.Key
+				num += 1
+
+				n := bytes.LastIndexByte(v.Meta.Key, ':')
+				if n < 0 {
+					continue
+				}
+
+				var (
+					id      = string(v.Meta.Key[n+1:])
+					posting localSearchPosting
+				)
+
+				if err := v.Decode(&posting); err != nil {
+					continue
+				}
+
+				hit, ok := hits[id]
+				if !ok {
+					if i > 0 {
+						continue
+					}
+					hit = &localSearchHit{
+						id:      id,
+						created: posting.Created,
+					}
+					hits[id] = hit
+				}
+
+				if hit.matched == i {
+					hit.weight += posting.Weight
+					hit.matched += 1
+				}
+			}
+
+			if !rs.Next {
+				break
+			}
+		}
+	}
+
+	var (
+		matches = []*localSearchHit{}
+		filter  = len(sq.Terms) > 0 || sq.CreatedMin > 0 || sq.CreatedMax > 0 ||
+			len(sq.Facets) > 0 || sq.ModName != ""
+		facets = map[string]map[uint32]uint64{}
+	)
+
+	for _, hit := range hits {
+
+		if hit.matched != len(terms) {
+			continue
+		}
+
+		if filter {
+
+			var doc localSearchDocument
+			if rs := store.DataLocal.NewReader(
+				api.NsTextSearchLocalDocument(bukname, hit.id)).Query(); !rs.OK() || rs.Decode(&doc) != nil {
+				continue
+			}
+
+			if !localSearchDocumentMatch(&doc, sq) {
+				continue
+			}
+
+			for _, name := range sq.Facets {
+				for _, id := range doc.Attrs[name] {
+					if _, ok := facets[name]; !ok {
+						facets[name] = map[uint32]uint64{}
+					}
+					facets[name][id] += 1
+				}
+			}
+		}
+
+		matches = append(matches, hit)
+	}
+
+	for _, name := range sq.Facets {
+
+		facet := &api.NodeListFacet{
+			Name: name,
+		}
+
+		for id, count := range facets[name] {
+			facet.Items = append(facet.Items, &api.NodeListFacetItem{
+				ID:    id,
+				Count: count,
+			})
+		}
+
+		sort.Slice(facet.Items, func(i, j int) bool {
+			if facet.Items[i].Count != facet.Items[j].Count {
+				return facet.Items[i].Count > facet.Items[j].Count
+			}
+			return facet.Items[i].ID < facet.Items[j].ID
+		})
+
+		ls.Facets = append(ls.Facets, facet)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].weight != matches[j].weight {
+			return matches[i].weight > matches[j].weight
+		}
+		return matches[i].created > matches[j].created
+	})
+
+	for i := int(qs.offset); i < len(matches) && i < int(qs.offset+qs.limit); i++ {
+
+		if rs := store.DataLocal.NewReader(
+			api.NsTextSearchCacheNodeEntry(bukname, matches[i].id)).Query(); rs.OK() {
+			var node api.Node
+			if err := rs.Decode(&node); err == nil && node.Status == 1 {
+				node.SearchScore = int64(matches[i].weight)
+				ls.Items = append(ls.Items, node)
+			}
+		}
+	}
+
+	ls.Kind = "NodeList"
+
+	if qs.Pager {
+		ls.Meta.TotalResults = uint64(len(matches))
+		ls.Meta.StartIndex = uint64(qs.offset)
+		ls.Meta.ItemsPerList = uint64(qs.limit)
+	}
+
+	return ls
+}
+
+func localSearchDocumentMatch(doc *localSearchDocument, sq *NodeSearchQuery) bool {
+
+	if sq.ModName != "" && doc.ModName != "" && doc.ModName != sq.ModName {
+		return false
+	}
+
+	if sq.CreatedMin > 0 && doc.Created < sq.CreatedMin {
+		return false
+	}
+
+	if sq.CreatedMax > 0 && doc.Created > sq.CreatedMax {
+		return false
+	}
+
+	for name, ids := range sq.Terms {
+		found := false
+		for _, id := range doc.Attrs[name] {
+			if found = _term_in_array(ids, id); found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func localSearchDocumentWeights(modname string, node *api.Node) map[string]int {
+
+	weights := map[string]int{}
+
+	for _, term := range searchDictTokens(modname, "", searchTextTokens(node.Title), true) {
+		weights[term] += localSearchWeightTitle
+	}
+
+	for _, nt := range node.Terms {
+		if nt.Type != api.TermTag {
+			continue
+		}
+		for _, ntv := range nt.Items {
+			for _, term := range searchDictTokens(modname, "", searchTextTokens(ntv.Title), true) {
+				weights[term] += localSearchWeightTags
+			}
+		}
+	}
+
+	for _, mf := range node.Fields {
+		if ft := mf.Attrs.Get("format"); len(ft) > 1 {
+			for _, term := range searchDictTokens(modname, "", searchTextTokens(TextHtml2Str(mf.Value)), true) {
+				weights[term] += localSearchWeightContent
+			}
+		}
+	}
+
+	return weights
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/hooto/hlog4g/hlog"
 	"github.com/lessos/lessgo/crypto/idhash"
 	"github.com/lessos/lessgo/types"
+	"github.com/lynkdb/iomix/rdb"
 
 	"github.com/hooto/hpress/api"
 	"github.com/hooto/hpress/config"
@@ -33,9 +35,75 @@ import (
 )
 
 type NodeSearchEngine interface {
-	Query(bucket string, q string, qs *QuerySet) api.NodeList
+	Query(bucket string, sq *NodeSearchQuery, qs *QuerySet) api.NodeList
 	Put(bucket string, node api.Node) error
 	ModelSet(bucket string, model *api.NodeModel) error
+	Status(bucket string) *api.NodeSearchIndexStatus
+	Indexed(bucket string) (map[string]bool, error)
+	Reindex(bucket string) error
+	Keywords(bucket string, q string) (map[string]int64, error)
+}
+
+// NodeSearchQuery is the structured query passed to the search engines,
+// a node matches a term filter if it has any of the listed term IDs.
+type NodeSearchQuery struct {
+	Text       string
+	ModName    string
+	Lang       string
+	Terms      map[string][]uint32
+	CreatedMin uint32
+	CreatedMax uint32
+	Facets     []string
+	Site       *api.Site
+	nolog      bool
+	nosuggest  bool
+}
+
+func NewNodeSearchQuery(text string) *NodeSearchQuery {
+	return &NodeSearchQuery{
+		Text:  text,
+		Terms: map[string][]uint32{},
+	}
+}
+
+func (sq *NodeSearchQuery) TermFilter(name string, ids ...uint32) {
+	if len(ids) > 0 {
+		sq.Terms[name] = append(sq.Terms[name], ids...)
+	}
+}
+
+func (sq *NodeSearchQuery) FacetSet(name string) {
+	for _, v := range sq.Facets {
+		if v == name {
+			return
+		}
+	}
+	sq.Facets = append(sq.Facets, name)
+}
+
+func searchNodeTermIDs(node *api.Node) map[string][]uint32 {
+
+	attrs := map[string][]uint32{}
+
+	for _, nt := range node.Terms {
+
+		switch nt.Type {
+
+		case api.TermTaxonomy:
+			if id, err := strconv.ParseUint(nt.Value, 10, 32); err == nil && id > 0 {
+				attrs[nt.Name] = []uint32{uint32(id)}
+			}
+
+		case api.TermTag:
+			for _, v := range nt.Items {
+				if v.ID > 0 {
+					attrs[nt.Name] = append(attrs[nt.Name], v.ID)
+				}
+			}
+		}
+	}
+
+	return attrs
 }
 
 var (
@@ -43,6 +111,7 @@ var (
 	searchLocker   sync.Mutex
 	searchIndexNum = 0
 	searchCaches   = map[string]*searchModuleCache{}
+	searchCachesMu sync.Mutex
 	nodeSearcher   NodeSearchEngine
 )
 
@@ -55,6 +124,20 @@ func data_search_node_label(name string) string {
 	return fmt.Sprintf("hpnode_%s", name)
 }
 
+func newNodeSearchEngine() (NodeSearchEngine, error) {
+
+	switch config.Config.SearchEngine {
+
+	case config.SearchEngineLocal:
+		return NewNodeLocalSearchEngine(config.Prefix)
+
+	case "", config.SearchEngineSphinx:
+		return NewNodeSphinxSearchEngine(config.Prefix)
+	}
+
+	return nil, fmt.Errorf("Invalid Search Engine (%s)", config.Config.SearchEngine)
+}
+
 func data_search_sync() error {
 
 	dataSearchOn := false
@@ -100,16 +183,9 @@ func data_search_sync() error {
 		return nil
 	}
 
-	searchLocker.Lock()
-	if !searchInited {
-		if engine, err := NewNodeSphinxSearchEngine(config.Prefix); err != nil {
-			return err
-		} else {
-			nodeSearcher = engine
-		}
-		searchInited = true
+	if err := searchEngineInit(); err != nil {
+		return err
 	}
-	searchLocker.Unlock()
 
 	var (
 		limit        int64 = 100
@@ -137,38 +213,10 @@ func data_search_sync() error {
 			continue
 		}
 
-		modid := idhash.HashToHexString([]byte(mod.Meta.Name), 12)
-
-		modCache, _ := searchCaches[mod.Meta.Name]
-		if modCache == nil {
-			modCache = &searchModuleCache{
-				termBufs:     map[string][]string{},
-				termTaxonomy: map[string]api.Term{},
-			}
-			searchCaches[mod.Meta.Name] = modCache
-		}
-
-		// Fetch Terms
-		for _, term := range mod.TermModels {
-
-			switch term.Type {
-
-			case api.TermTaxonomy:
-
-				table := fmt.Sprintf("hpt_%s_%s", modid, term.Meta.Name)
-				qs := store.Data.NewQueryer().From(table).Limit(2000)
-
-				if rs, err := store.Data.Query(qs); err == nil && len(rs) > 0 {
-					for _, v := range rs {
-						modCache.termTaxonomy[term.Meta.Name+"."+v.Field("id").String()] = api.Term{
-							ID:    v.Field("id").Uint32(),
-							Title: v.Field("title").String(),
-						}
-						// fmt.Println(table, v.Field("id").Uint32(), v.Field("title").String())
-					}
-				}
-			}
-		}
+		var (
+			modid    = idhash.HashToHexString([]byte(mod.Meta.Name), 12)
+			modCache = searchModuleCacheRefresh(mod)
+		)
 
 		for _, model := range mod.NodeModels {
 
@@ -211,141 +259,215 @@ func data_search_sync() error {
 						break
 					}
 
-					item := api.Node{
-						ID:      v.Field("id").String(),
-						PID:     v.Field("pid").String(),
-						Status:  v.Field("status").Int16(),
-						UserID:  v.Field("userid").String(),
-						Created: v.Field("created").Uint32(),
-						Updated: v.Field("updated").Uint32(),
-					}
+					item := searchNodeEntry(model, modCache, v)
 
-					if model.Extensions.AccessCounter {
-						item.ExtAccessCounter = v.Field("ext_access_counter").Uint32()
-					}
+					searchSuggestPut(tblname, &item)
 
-					if model.Extensions.CommentEnable {
-						if model.Extensions.CommentPerEntry && v.Field("ext_comment_perentry").Bool() == false {
-							item.ExtCommentEnable = false
-							item.ExtCommentPerEntry = false
-						} else {
-							item.ExtCommentEnable = true
-							item.ExtCommentPerEntry = true
-						}
-					}
+					// fmt.Println(id, v.Field("title").String())
 
-					if model.Extensions.Permalink != "" && v.Field("ext_permalink_name").String() != "" {
-						item.ExtPermalinkName = v.Field("ext_permalink_name").String()
-						item.SelfLink = fmt.Sprintf("%s", item.ExtPermalinkName)
-					} else {
-						item.SelfLink = fmt.Sprintf("%s.html", item.ID)
+					if err := nodeSearcher.Put(tblname, item); err != nil {
+						return err
 					}
 
-					for _, field := range model.Fields {
+					indexNum += 1
+				}
 
-						nodeField := api.NodeField{
-							Name:  field.Name,
-							Value: v.Field("field_" + field.Name).String(),
-						}
+				if len(rs) < int(limit) {
+					break
+				}
 
-						if field.Type == "text" &&
-							len(v.Field("field_"+field.Name+"_attrs").String()) > 10 {
+				offset += limit
+				q.Offset(offset)
+			}
 
-							var attrs types.KvPairs
-							if err := v.Field("field_" + field.Name + "_attrs").JsonDecode(&attrs); err == nil {
-								nodeField.Attrs = attrs
-							}
-						}
+			if indexNum > 0 {
+				cfgs.Set("index_updated", indexUpdated)
+				if rs := store.DataLocal.NewWriter(kvKey, cfgs).Commit(); !rs.OK() {
+					hlog.Printf("warn", "search index error")
+				}
+				hlog.Printf("info", "search data sync %d at %v",
+					indexNum, time.Since(indexStart))
+			}
+		}
+	}
 
-						if l := field.Attrs.Get("langs"); len(l) > 3 {
+	return nil
+}
 
-							if len(v.Field("field_"+field.Name+"_langs").String()) > 5 {
-								var node_langs api.NodeFieldLangs
-								if err := v.Field("field_" + field.Name + "_langs").JsonDecode(&node_langs); err == nil {
-									nodeField.Langs = &node_langs
-								}
-							}
-						}
+func searchEngineInit() error {
 
-						if field.Name == "title" {
-							item.Title = nodeField.Value
-						}
+	searchLocker.Lock()
+	defer searchLocker.Unlock()
 
-						item.Fields = append(item.Fields, &nodeField)
-					}
+	if !searchInited {
+		engine, err := newNodeSearchEngine()
+		if err != nil {
+			return err
+		}
+		nodeSearcher = engine
+		searchInited = true
+	}
+
+	return nil
+}
+
+func searchModuleCacheRefresh(mod *api.Spec) *searchModuleCache {
 
-					for _, term := range model.Terms {
+	modid := idhash.HashToHexString([]byte(mod.Meta.Name), 12)
 
-						switch term.Type {
-						case api.TermTaxonomy:
+	// the refreshed cache replaces the previous one, the caches returned
+	// to the sync worker and to the index repair are never written again
+	modCache := &searchModuleCache{
+		termBufs:     map[string][]string{},
+		termTaxonomy: map[string]api.Term{},
+	}
 
-							if ttv, ok := modCache.termTaxonomy[term.Meta.Name+"."+v.Field("term_"+term.Meta.Name).String()]; ok {
-								termItem := api.NodeTerm{
-									Name:  term.Meta.Name,
-									Value: v.Field("term_" + term.Meta.Name).String(),
-									Type:  term.Type,
-								}
+	// Fetch Terms
+	for _, term := range mod.TermModels {
 
-								termItem.Items = append(termItem.Items, ttv)
+		switch term.Type {
 
-								item.Terms = append(item.Terms, termItem)
-							}
+		case api.TermTaxonomy:
 
-						case api.TermTag:
+			table := fmt.Sprintf("hpt_%s_%s", modid, term.Meta.Name)
+			qs := store.Data.NewQueryer().From(table).Limit(2000)
 
-							tags := strings.Split(v.Field("term_"+term.Meta.Name).String(), ",")
+			if rs, err := store.Data.Query(qs); err == nil && len(rs) > 0 {
+				for _, v := range rs {
+					modCache.termTaxonomy[term.Meta.Name+"."+v.Field("id").String()] = api.Term{
+						ID:    v.Field("id").Uint32(),
+						Title: v.Field("title").String(),
+					}
+					// fmt.Println(table, v.Field("id").Uint32(), v.Field("title").String())
+				}
+			}
+		}
+	}
 
-							if len(tags) > 0 {
-								termItem := api.NodeTerm{
-									Name:  term.Meta.Name,
-									Value: v.Field("term_" + term.Meta.Name).String(),
-									Type:  term.Type,
-								}
+	searchCachesMu.Lock()
+	searchCaches[mod.Meta.Name] = modCache
+	searchCachesMu.Unlock()
 
-								for _, vtag := range tags {
-									termItem.Items = append(termItem.Items, api.Term{
-										Title: vtag,
-									})
+	return modCache
+}
 
-								}
+func searchNodeEntry(model *api.NodeModel, modCache *searchModuleCache, v rdb.Entry) api.Node {
 
-								item.Terms = append(item.Terms, termItem)
-							}
-						}
-					}
+	item := api.Node{
+		ID:      v.Field("id").String(),
+		PID:     v.Field("pid").String(),
+		Status:  v.Field("status").Int16(),
+		UserID:  v.Field("userid").String(),
+		Created: v.Field("created").Uint32(),
+		Updated: v.Field("updated").Uint32(),
+	}
 
-					// fmt.Println(id, v.Field("title").String())
+	if model.Extensions.AccessCounter {
+		item.ExtAccessCounter = v.Field("ext_access_counter").Uint32()
+	}
 
-					if err := nodeSearcher.Put(tblname, item); err != nil {
-						return err
-					}
+	if model.Extensions.CommentEnable {
+		if model.Extensions.CommentPerEntry && v.Field("ext_comment_perentry").Bool() == false {
+			item.ExtCommentEnable = false
+			item.ExtCommentPerEntry = false
+		} else {
+			item.ExtCommentEnable = true
+			item.ExtCommentPerEntry = true
+		}
+	}
 
-					indexNum += 1
+	if model.Extensions.Permalink != "" && v.Field("ext_permalink_name").String() != "" {
+		item.ExtPermalinkName = v.Field("ext_permalink_name").String()
+		item.SelfLink = fmt.Sprintf("%s", item.ExtPermalinkName)
+	} else {
+		item.SelfLink = fmt.Sprintf("%s.html", item.ID)
+	}
+
+	for _, field := range model.Fields {
+
+		nodeField := api.NodeField{
+			Name:  field.Name,
+			Value: v.Field("field_" + field.Name).String(),
+		}
+
+		if field.Type == "text" &&
+			len(v.Field("field_"+field.Name+"_attrs").String()) > 10 {
+
+			var attrs types.KvPairs
+			if err := v.Field("field_" + field.Name + "_attrs").JsonDecode(&attrs); err == nil {
+				nodeField.Attrs = attrs
+			}
+		}
+
+		if l := field.Attrs.Get("langs"); len(l) > 3 {
+
+			if len(v.Field("field_"+field.Name+"_langs").String()) > 5 {
+				var node_langs api.NodeFieldLangs
+				if err := v.Field("field_" + field.Name + "_langs").JsonDecode(&node_langs); err == nil {
+					nodeField.Langs = &node_langs
 				}
+			}
+		}
 
-				if len(rs) < int(limit) {
-					break
+		if field.Name == "title" {
+			item.Title = nodeField.Value
+		}
+
+		item.Fields = append(item.Fields, &nodeField)
+	}
+
+	for _, term := range model.Terms {
+
+		switch term.Type {
+		case api.TermTaxonomy:
+
+			if ttv, ok := modCache.termTaxonomy[term.Meta.Name+"."+v.Field("term_"+term.Meta.Name).String()]; ok {
+				termItem := api.NodeTerm{
+					Name:  term.Meta.Name,
+					Value: v.Field("term_" + term.Meta.Name).String(),
+					Type:  term.Type,
 				}
 
-				offset += limit
-				q.Offset(offset)
+				termItem.Items = append(termItem.Items, ttv)
+
+				item.Terms = append(item.Terms, termItem)
 			}
 
-			if indexNum > 0 {
-				cfgs.Set("index_updated", indexUpdated)
-				if rs := store.DataLocal.NewWriter(kvKey, cfgs).Commit(); !rs.OK() {
-					hlog.Printf("warn", "search index error")
+		case api.TermTag:
+
+			var (
+				tags   = strings.Split(v.Field("term_"+term.Meta.Name).String(), ",")
+				tagIds = strings.Split(v.Field("term_"+term.Meta.Name+"_idx").String(), ",")
+			)
+
+			if len(tags) > 0 {
+				termItem := api.NodeTerm{
+					Name:  term.Meta.Name,
+					Value: v.Field("term_" + term.Meta.Name).String(),
+					Type:  term.Type,
 				}
-				hlog.Printf("info", "search data sync %d at %v",
-					indexNum, time.Since(indexStart))
+
+				for i, vtag := range tags {
+					tagItem := api.Term{
+						Title: vtag,
+					}
+					if len(tagIds) == len(tags) {
+						if tid, err := strconv.ParseUint(tagIds[i], 10, 32); err == nil {
+							tagItem.ID = uint32(tid)
+						}
+					}
+					termItem.Items = append(termItem.Items, tagItem)
+				}
+
+				item.Terms = append(item.Terms, termItem)
 			}
 		}
 	}
 
-	return nil
+	return item
 }
 
-func (q *QuerySet) NodeListSearch(qry string) api.NodeList {
+func (q *QuerySet) NodeListSearch(sq *NodeSearchQuery) api.NodeList {
 
 	var rsp api.NodeList
 
@@ -354,10 +476,79 @@ func (q *QuerySet) NodeListSearch(qry string) api.NodeList {
 		return rsp
 	}
 
+	if sq.ModName == "" {
+		sq.ModName = q.ModName
+	}
+
 	table := fmt.Sprintf("hpn_%s_%s",
 		idhash.HashToHexString([]byte(q.ModName), 12), q.Table)
 
-	return nodeSearcher.Query(table, qry, q)
+	rsp = nodeSearcher.Query(table, sq, q)
+	if rsp.Error == nil {
+		searchExcerptsFill(rsp.Items, sq.Text)
+		searchFacetsFill(q.ModName, rsp.Facets)
+		if len(rsp.Items) == 0 && !sq.nosuggest {
+			if buk := searchBucketEntry(table); buk != nil {
+				rsp.SearchSuggest = searchDidYouMean(sq.Text, []searchBucket{*buk})
+			}
+		}
+	}
+
+	if rsp.Error == nil && !sq.nolog {
+		num := rsp.Meta.TotalResults
+		if num == 0 {
+			num = uint64(len(rsp.Items))
+		}
+		rsp.SearchLogID = SearchLogPut(sq.Text, q.ModName, q.Table, num)
+	}
+
+	return rsp
+}
+
+func searchFacetsFill(modname string, facets []*api.NodeListFacet) {
+
+	for _, facet := range facets {
+
+		model, err := config.SpecTermModel(modname, facet.Name)
+		if err != nil || len(facet.Items) < 1 {
+			continue
+		}
+
+		switch model.Type {
+
+		case api.TermTaxonomy:
+
+			_termTaxonomyCacheRefresh(modname, facet.Name)
+
+			for _, v := range facet.Items {
+				if te := TermTaxonomyCacheEntry(modname, facet.Name, v.ID); te != nil {
+					v.Title = te.Title
+				}
+			}
+
+		case api.TermTag:
+
+			ids := []interface{}{}
+			for _, v := range facet.Items {
+				ids = append(ids, v.ID)
+			}
+
+			table := fmt.Sprintf("hpt_%s_%s", idhash.HashToHexString([]byte(modname), 12), facet.Name)
+			qs := store.Data.NewQueryer().From(table).Limit(int64(len(ids)))
+			qs.Where().And("id.in", ids...)
+
+			if rs, err := store.Data.Query(qs); err == nil {
+				for _, v := range rs {
+					for _, item := range facet.Items {
+						if item.ID == v.Field("id").Uint32() {
+							item.Title = v.Field("title").String()
+							break
+						}
+					}
+				}
+			}
+		}
+	}
 }
 
 func hex16ToUint64(str string) uint64 {
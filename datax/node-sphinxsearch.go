@@ -21,11 +21,14 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"html"
 	"io/ioutil"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"text/template"
@@ -36,10 +39,19 @@ import (
 	"github.com/lessos/lessgo/types"
 
 	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
 	"github.com/hooto/hpress/datax/sphinxsearch"
 	"github.com/hooto/hpress/store"
 )
 
+const (
+	sphIndexedScanLimit = 1000
+
+	// sphSchemaVersion is the version of the index attributes, the full
+	// indexes of the buckets are rebuilt when it changes
+	sphSchemaVersion = 2
+)
+
 type NodeSphinxSearchEngine struct {
 	mu                sync.Mutex
 	prefix            string
@@ -69,6 +81,7 @@ type SphinxSearchConfig struct {
 type SphinxSearchConfigBucketEntry struct {
 	Name             string         `json:"name"`
 	StatsFullIndexed int64          `json:"stats_full_indexed"`
+	SchemaVersion    int            `json:"schema_version,omitempty"`
 	Model            *api.NodeModel `json:"model"`
 	statsActive      bool
 }
@@ -80,6 +93,8 @@ type sphinxSearchBucketActive struct {
 	puts          []api.Node
 	deltaIndexNum int
 	model         *api.NodeModel
+	statsDelta    int64
+	lastError     string
 }
 
 const (
@@ -122,13 +137,21 @@ func NewNodeSphinxSearchEngine(prefix string) (NodeSearchEngine, error) {
 
 	json.DecodeFile(engine.cfgConfigPath, &engine.cfgs)
 
+	for _, buk := range engine.cfgs.Buckets {
+		if buk.SchemaVersion != sphSchemaVersion {
+			buk.StatsFullIndexed = 0
+		}
+	}
+
 	engine.cfgs.Prefix = filepath.Clean(prefix)
 	engine.cfgs.Daemon.CpuCoreNum = runtime.NumCPU()
 	engine.cfgs.Daemon.MaxChildren = runtime.NumCPU() * 2
 
 	engine.configRefresh()
 
-	go engine.run()
+	if !searchOffline {
+		go engine.run()
+	}
 
 	return engine, nil
 }
@@ -358,8 +381,11 @@ func (it *NodeSphinxSearchEngine) runAction() {
 		if (tn - buk.StatsFullIndexed) > 86400 {
 			if err := it.indexFull(active); err == nil {
 				buk.StatsFullIndexed = tn
+				buk.SchemaVersion = sphSchemaVersion
+				active.lastError = ""
 				json.EncodeToFile(it.cfgs, it.cfgConfigPath, "  ")
 			} else {
+				active.lastError = "index/full " + err.Error()
 				hlog.Printf("error", "index/full ER %s", err.Error())
 			}
 		}
@@ -367,7 +393,10 @@ func (it *NodeSphinxSearchEngine) runAction() {
 		if len(active.deltas) > active.deltaIndexNum {
 			if n, err := it.indexDelta(active); err == nil {
 				active.deltaIndexNum = n
+				active.statsDelta = tn
+				active.lastError = ""
 			} else {
+				active.lastError = "index/delta " + err.Error()
 				hlog.Printf("error", "index/delta ER %s", err.Error())
 			}
 		}
@@ -378,6 +407,7 @@ func (it *NodeSphinxSearchEngine) runAction() {
 				active.deltas = []api.Node{}
 				active.deltaIndexNum = 0
 			} else {
+				active.lastError = "index/merge " + err.Error()
 				hlog.Printf("error", "index/merge ER %s", err.Error())
 			}
 		}
@@ -551,6 +581,19 @@ func (it *NodeSphinxSearchEngine) dataRefresh(
 		fmt.Sprintf(`<sphinx:attr name="%s" type="timestamp"/>`, "created"),
 	}
 
+	if model := it.active(bukname).model; model != nil {
+		for _, term := range model.Terms {
+			switch term.Type {
+			case api.TermTaxonomy:
+				schemas = append(schemas, fmt.Sprintf(`<sphinx:attr name="%s" type="int" bits="32" default="0"/>`,
+					sphTermAttrName(term.Meta.Name)))
+			case api.TermTag:
+				schemas = append(schemas, fmt.Sprintf(`<sphinx:attr name="%s" type="multi"/>`,
+					sphTermAttrName(term.Meta.Name)))
+			}
+		}
+	}
+
 	fpbuf.WriteString(fmt.Sprintf(sphXmlPipeSchema, strings.Join(schemas, "\n")))
 
 	if fn != nil {
@@ -621,16 +664,164 @@ func (it *NodeSphinxSearchEngine) Put(bukname string, node api.Node) error {
 	return nil
 }
 
-func (it *NodeSphinxSearchEngine) Query(bukname string, q string, qs *QuerySet) api.NodeList {
-
-	var ls api.NodeList
+func (it *NodeSphinxSearchEngine) newClient() (*sphinxsearch.Client, error) {
 
-	opts := sphinxsearch.DefaultOptions
+	opts := *sphinxsearch.DefaultOptions
 	opts.MaxQueryTime = 5000
 	opts.Socket = it.dataPath + "/searchd.sock"
 
-	client := sphinxsearch.NewClient(opts)
+	client := sphinxsearch.NewClient(&opts)
 	if err := client.Error(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (it *NodeSphinxSearchEngine) modName(bukname string) string {
+	if active := it.active(bukname); active.model != nil {
+		return active.model.ModName
+	}
+	return ""
+}
+
+func (it *NodeSphinxSearchEngine) Keywords(bukname, q string) (map[string]int64, error) {
+
+	client, err := it.newClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	kws := map[string]int64{}
+
+	for _, idxtype := range []string{"full", "delta"} {
+
+		ls, err := client.BuildKeywords(sphTextFilter(it.modName(bukname), "", q, false), bukname+"_"+idxtype, true)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range ls {
+			kws[strings.ToLower(v.Tokenized)] += int64(v.Docs)
+		}
+	}
+
+	return kws, nil
+}
+
+func (it *NodeSphinxSearchEngine) Status(bukname string) *api.NodeSearchIndexStatus {
+
+	status := &api.NodeSearchIndexStatus{
+		Bucket: bukname,
+		Engine: config.SearchEngineSphinx,
+	}
+
+	if ids, err := it.Indexed(bukname); err == nil {
+		status.Documents = int64(len(ids))
+	}
+
+	if buk := it.bucket(bukname, false); buk != nil {
+		status.FullIndexed = buk.StatsFullIndexed
+	}
+
+	active := it.active(bukname)
+
+	active.mu.Lock()
+	status.DeltaIndexed = active.statsDelta
+	status.DeltaPending = int64(len(active.puts) + len(active.deltas))
+	status.Error = active.lastError
+	active.mu.Unlock()
+
+	return status
+}
+
+// Indexed returns the node ids of the published documents that searchd
+// serves from the full and delta indexes of the bucket, the documents are
+// scanned in the order of the document id, sphIndexedScanLimit per query.
+func (it *NodeSphinxSearchEngine) Indexed(bukname string) (map[string]bool, error) {
+
+	// the command line tools do not start searchd
+	if searchOffline {
+		return nil, errors.New("searchd is not running offline, check the bucket with the API of the running server")
+	}
+
+	client, err := it.newClient()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	client.SetMatchMode(sphinxsearch.SPH_MATCH_EXTENDED)
+	client.SetSortMode(sphinxsearch.SPH_SORT_EXTENDED, "@id ASC")
+	client.SetLimits(0, sphIndexedScanLimit, sphIndexedScanLimit, 0)
+	client.SetFilter("status", []uint64{1}, false)
+
+	var (
+		ids    = map[string]bool{}
+		offset = uint64(0)
+	)
+
+	for {
+
+		client.SetIDRange(offset, math.MaxUint64)
+
+		rss, err := client.Query("", bukname, "")
+		if err != nil {
+			return nil, err
+		}
+
+		idIdx := -1
+		for i, v := range rss.AttrNames {
+			if v == "nid" {
+				idIdx = i
+				break
+			}
+		}
+		if idIdx == -1 {
+			return nil, errors.New("attribute nid not found in index " + bukname)
+		}
+
+		for _, v := range rss.Matches {
+			if idIdx < len(v.AttrValues) {
+				if nid, ok := v.AttrValues[idIdx].(string); ok && nid != "" {
+					ids[nid] = true
+				}
+			}
+			offset = v.DocId + 1
+		}
+
+		if len(rss.Matches) < sphIndexedScanLimit || offset == 0 {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
+// Reindex resets the full index stats of the bucket, the worker rebuilds
+// the full index from the node caches on the next run.
+func (it *NodeSphinxSearchEngine) Reindex(bukname string) error {
+
+	buk := it.bucket(bukname, false)
+	if buk == nil {
+		return errors.New("Bucket Not Found")
+	}
+
+	it.mu.Lock()
+	defer it.mu.Unlock()
+
+	buk.StatsFullIndexed = 0
+
+	return json.EncodeToFile(it.cfgs, it.cfgConfigPath, "  ")
+}
+
+func (it *NodeSphinxSearchEngine) Query(bukname string, sq *NodeSearchQuery, qs *QuerySet) api.NodeList {
+
+	var ls api.NodeList
+
+	client, err := it.newClient()
+	if err != nil {
 		ls.Error = types.NewErrorMeta(api.ErrCodeInternalError, err.Error())
 		return ls
 	}
@@ -646,7 +837,23 @@ func (it *NodeSphinxSearchEngine) Query(bukname string, q string, qs *QuerySet)
 	client.SetFilter("status", []uint64{1}, false)
 	client.SetMatchMode(sphinxsearch.SPH_MATCH_EXTENDED)
 
-	rss, err := client.Query(q, bukname, "")
+	for name, ids := range sq.Terms {
+		vals := []uint64{}
+		for _, id := range ids {
+			vals = append(vals, uint64(id))
+		}
+		client.SetFilter(sphTermAttrName(name), vals, false)
+	}
+
+	if sq.CreatedMin > 0 || sq.CreatedMax > 0 {
+		tmax := uint64(sq.CreatedMax)
+		if tmax == 0 {
+			tmax = math.MaxUint32
+		}
+		client.SetFilterRange("created", uint64(sq.CreatedMin), tmax, false)
+	}
+
+	rss, err := client.Query(sphTextFilter(sq.ModName, sq.Lang, sq.Text, false), bukname, "")
 	if err != nil {
 		ls.Error = types.NewErrorMeta(api.ErrCodeInternalError, err.Error())
 		return ls
@@ -674,11 +881,22 @@ func (it *NodeSphinxSearchEngine) Query(bukname string, q string, qs *QuerySet)
 			api.NsTextSearchCacheNodeEntry(bukname, fmt.Sprintf("%s", v.AttrValues[id_idx]))).Query(); rs.OK() {
 			var node api.Node
 			if err := rs.Decode(&node); err == nil {
+				node.SearchScore = int64(v.Weight)
 				ls.Items = append(ls.Items, node)
 			}
 		}
 	}
 
+	if len(ls.Items) > 0 {
+		it.excerptsBuild(client, bukname, sq.Text, ls.Items)
+	}
+
+	for _, name := range sq.Facets {
+		if facet := it.facetBuild(client, bukname, sq, name); facet != nil {
+			ls.Facets = append(ls.Facets, facet)
+		}
+	}
+
 	ls.Kind = "NodeList"
 
 	if qs.Pager {
@@ -690,20 +908,111 @@ func (it *NodeSphinxSearchEngine) Query(bukname string, q string, qs *QuerySet)
 	return ls
 }
 
-func sphTextFilter(txt string) string {
+func (it *NodeSphinxSearchEngine) facetBuild(client *sphinxsearch.Client,
+	bukname string, sq *NodeSearchQuery, name string) *api.NodeListFacet {
 
-	for _, v := range []string{
-		`"`,
-		"'",
-		"<",
-		">",
-		"\r",
-		"\n",
-	} {
-		txt = strings.Replace(txt, v, " ", -1)
+	client.SetLimits(0, 100, 1000, 0)
+	client.SetGroupBy(sphTermAttrName(name), sphinxsearch.SPH_GROUPBY_ATTR, "@count desc")
+	defer client.ResetGroupBy()
+
+	rss, err := client.Query(sphTextFilter(sq.ModName, sq.Lang, sq.Text, false), bukname, "")
+	if err != nil {
+		return nil
+	}
+
+	var (
+		id_idx    = -1
+		count_idx = -1
+		facet     = &api.NodeListFacet{
+			Name: name,
+		}
+	)
+
+	for i, v := range rss.AttrNames {
+		switch v {
+		case "@groupby":
+			id_idx = i
+		case "@count":
+			count_idx = i
+		}
 	}
 
-	return txt
+	if id_idx == -1 || count_idx == -1 {
+		return nil
+	}
+
+	for _, v := range rss.Matches {
+
+		if id_idx >= len(v.AttrValues) || count_idx >= len(v.AttrValues) {
+			continue
+		}
+
+		id, _ := strconv.ParseUint(fmt.Sprintf("%v", v.AttrValues[id_idx]), 10, 32)
+		count, _ := strconv.ParseUint(fmt.Sprintf("%v", v.AttrValues[count_idx]), 10, 64)
+		if id < 1 || count < 1 {
+			continue
+		}
+
+		facet.Items = append(facet.Items, &api.NodeListFacetItem{
+			ID:    uint32(id),
+			Count: count,
+		})
+	}
+
+	return facet
+}
+
+func (it *NodeSphinxSearchEngine) excerptsBuild(client *sphinxsearch.Client,
+	bukname, q string, items []api.Node) {
+
+	var (
+		opts = searchExcerptOptions()
+		docs = []string{}
+	)
+
+	for _, v := range items {
+		docs = append(docs, html.EscapeString(v.Title), html.EscapeString(searchNodeContent(&v)))
+	}
+
+	rs, err := client.BuildExcerpts(docs, bukname+"_full", strings.Join(searchTextTokens(q), " "), sphinxsearch.ExcerptsOpts{
+		BeforeMatch:   opts.BeforeMatch,
+		AfterMatch:    opts.AfterMatch,
+		Limit:         opts.Length,
+		SinglePassage: true,
+		HtmlStripMode: "none",
+	})
+	if err != nil || len(rs) != len(docs) {
+		return
+	}
+
+	// the documents are not segmented as the index is, searchd finds no
+	// match in the CJK text without spaces, these are highlighted locally
+	terms := searchTextTokens(q)
+
+	for i, v := range items {
+
+		title, content := rs[2*i], rs[2*i+1]
+
+		if !strings.Contains(title, opts.BeforeMatch) {
+			title = searchExcerptHighlight(v.Title, terms, opts, 0)
+		}
+		if !strings.Contains(content, opts.BeforeMatch) {
+			content = searchExcerptHighlight(searchNodeContent(&v), terms, opts, opts.Length)
+		}
+
+		items[i].SearchExcerpt = &api.NodeSearchExcerpt{
+			Title:   title,
+			Content: content,
+		}
+	}
+}
+
+func sphTextFilter(modname, lang, txt string, index bool) string {
+	return strings.Join(searchDictTokens(modname, lang, searchTextTokens(txt), index), " ")
+}
+
+func sphTermAttrName(name string) string {
+	return "term_" + name + "_id"
 }
 
 func sphDocumentXml(node *api.Node, active *sphinxSearchBucketActive) string {
@@ -713,11 +1022,25 @@ func sphDocumentXml(node *api.Node, active *sphinxSearchBucketActive) string {
 		return ""
 	}
 
+	modname := ""
+	if active.model != nil {
+		modname = active.model.ModName
+	}
+
 	xml := fmt.Sprintf(`<sphinx:document id="%d">`, u64)
 
 	xml += fmt.Sprintf(`<%s>%s</%s>`, "nid", node.ID, "nid")
 	xml += fmt.Sprintf(`<%s>%d</%s>`, "status", node.Status, "status")
-	xml += fmt.Sprintf(`<%s><![CDATA[%s]]></%s>`, "title", sphTextFilter(node.Title), "title")
+	xml += fmt.Sprintf(`<%s>%d</%s>`, "created", node.Created, "created")
+
+	for name, ids := range searchNodeTermIDs(node) {
+		vals := []string{}
+		for _, id := range ids {
+			vals = append(vals, strconv.FormatUint(uint64(id), 10))
+		}
+		xml += fmt.Sprintf(`<%s>%s</%s>`, sphTermAttrName(name), strings.Join(vals, ","), sphTermAttrName(name))
+	}
+	xml += fmt.Sprintf(`<%s><![CDATA[%s]]></%s>`, "title", sphTextFilter(modname, "", node.Title, true), "title")
 
 	if len(node.Terms) > 0 {
 		terms := ""
@@ -733,7 +1056,7 @@ func sphDocumentXml(node *api.Node, active *sphinxSearchBucketActive) string {
 			}
 		}
 		if len(terms) > 0 {
-			xml += fmt.Sprintf(`<%s><![CDATA[%s]]></%s>`, "term_tags", sphTextFilter(terms), "term_tags")
+			xml += fmt.Sprintf(`<%s><![CDATA[%s]]></%s>`, "term_tags", sphTextFilter(modname, "", terms, true), "term_tags")
 		}
 	}
 
@@ -744,7 +1067,7 @@ func sphDocumentXml(node *api.Node, active *sphinxSearchBucketActive) string {
 		}
 	}
 	if len(content) > 0 {
-		xml += fmt.Sprintf(`<%s><![CDATA[%s]]></%s>`, "content", sphTextFilter(content), "content")
+		xml += fmt.Sprintf(`<%s><![CDATA[%s]]></%s>`, "content", sphTextFilter(modname, "", content, true), "content")
 	}
 
 	xml += "</sphinx:document>\n"
@@ -382,6 +382,13 @@ func (q *QuerySet) NodeEntry() api.Node {
 		rsp.ExtNodeRefer = rs.Field("ext_node_refer").String()
 	}
 
+	if rsp.Model.Extensions.Seo && len(rs.Field("ext_seo").String()) > 2 {
+		var seo api.NodeSeo
+		if err := rs.Field("ext_seo").JsonDecode(&seo); err == nil {
+			rsp.ExtSeo = &seo
+		}
+	}
+
 	rsp.Kind = "Node"
 
 	// qryhash := q.Hash()
@@ -0,0 +1,184 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+const (
+	pageCacheDataTagsKey = "__page_cache_tags__"
+)
+
+func PageCacheTTL() int64 {
+	ttl, _ := strconv.ParseInt(config.SysConfigList.FetchString("frontend_page_cache_ttl"), 10, 64)
+	if ttl < 0 {
+		ttl = 0
+	}
+	return ttl
+}
+
+func PageCacheKey(args ...string) string {
+	h := md5.Sum([]byte(strings.Join(args, "\n")))
+	return hex.EncodeToString(h[:])
+}
+
+func PageCacheTagNode(modname, model, id string) string {
+	return "node:" + modname + ":" + model + ":" + id
+}
+
+func PageCacheTagNodes(modname, model string) string {
+	return "nodes:" + modname + ":" + model
+}
+
+func PageCacheTagTerm(modname, model string, id uint32) string {
+	return "term:" + modname + ":" + model + ":" + strconv.FormatUint(uint64(id), 10)
+}
+
+func PageCacheTagTerms(modname, model string) string {
+	return "terms:" + modname + ":" + model
+}
+
+// PageCacheTagPagelets tags all of the cached pagelet fragments, it is
+// purged by the changes of the settings, sites and menus.
+func PageCacheTagPagelets() string {
+	return "pagelets"
+}
+
+// PageCacheTagMenu tags the pages which render the named menu
+func PageCacheTagMenu(name string) string {
+	return "menu:" + name
+}
+
+// PageCacheDataTags returns the tags which are added to the page data by
+// the template functions, e.g. pagelets and menus, while the page is
+// rendered, the output cache of the page is purged with them.
+func PageCacheDataTags(data map[string]interface{}) []string {
+	ls, _ := data[pageCacheDataTagsKey].([]string)
+	return ls
+}
+
+func pageCacheDataTagsAdd(data map[string]interface{}, tags ...string) {
+
+	ls, _ := data[pageCacheDataTagsKey].([]string)
+
+	for _, tag := range tags {
+		found := false
+		for _, v := range ls {
+			if v == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ls = append(ls, tag)
+		}
+	}
+
+	data[pageCacheDataTagsKey] = ls
+}
+
+func PageCacheEntry(key string) *api.PageCacheEntry {
+
+	var entry api.PageCacheEntry
+	if rs := store.DataLocal.NewReader(api.NsPageCache(key)).Query(); rs.OK() {
+		if err := rs.Decode(&entry); err == nil {
+			return &entry
+		}
+	}
+
+	return nil
+}
+
+// PageCachePut saves the page and an index entry for each of its tags, so
+// that the page can be purged by any of the nodes or terms it rendered.
+func PageCachePut(key string, entry *api.PageCacheEntry, ttl int64) {
+
+	if ttl < 1 {
+		return
+	}
+
+	entry.Created = time.Now().Unix()
+
+	for _, tag := range entry.Tags {
+		store.DataLocal.NewWriter(api.NsPageCacheTag(tag, key), "1").ExpireSet(ttl * 1000).Commit()
+	}
+
+	store.DataLocal.NewWriter(api.NsPageCache(key), entry).ExpireSet(ttl * 1000).Commit()
+}
+
+// PageCachePurge removes the cached pages which are tagged with any of tags,
+// and bumps the versions of the tags.
+func PageCachePurge(tags ...string) {
+
+	tn := uint32(time.Now().Unix())
+
+	for _, tag := range tags {
+
+		store.DataLocal.NewWriter(api.NsPageCacheTagVersion(tag), tn).Commit()
+
+		var (
+			prefix = api.NsPageCacheTag(tag, "")
+			offset = prefix
+		)
+
+		for {
+
+			rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, prefix).
+				LimitNumSet(100).Query()
+
+			for _, v := range rs.Items {
+				offset = v.Meta.Key
+				if key := string(v.Meta.Key[len(prefix):]); key != "" {
+					store.DataLocal.NewWriter(api.NsPageCache(key), nil).ModeDeleteSet(true).Commit()
+				}
+				store.DataLocal.NewWriter(v.Meta.Key, nil).ModeDeleteSet(true).Commit()
+			}
+
+			if !rs.Next {
+				break
+			}
+		}
+	}
+}
+
+// PageCacheTagVersion returns the time of the last change of the tag, e.g.
+// a node of the model is created, updated or deleted for the tag of the
+// node list, or 0 if the tag is never purged.
+func PageCacheTagVersion(tag string) uint32 {
+	var v uint32
+	if rs := store.DataLocal.NewReader(api.NsPageCacheTagVersion(tag)).Query(); rs.OK() {
+		rs.Decode(&v)
+	}
+	return v
+}
+
+// PageCachePurgeNode purges the pages of the node and the lists of its model
+func PageCachePurgeNode(modname, model, id string) {
+	PageCachePurge(PageCacheTagNode(modname, model, id), PageCacheTagNodes(modname, model))
+}
+
+// PageCachePurgeTerm purges the pages of the term and the lists of its model
+func PageCachePurgeTerm(modname, model string, id uint32) {
+	PageCachePurge(PageCacheTagTerm(modname, model, id), PageCacheTagTerms(modname, model))
+}
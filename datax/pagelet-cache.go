@@ -0,0 +1,132 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+)
+
+const (
+	pageletCacheTTLMax = 86400
+)
+
+// pageletCacheArgs takes the cache_ttl and cache_vary options out of the
+// pagelet arguments
+func pageletCacheArgs(args []string) ([]string, int64, []string) {
+
+	var (
+		rs   = []string{}
+		ttl  int64
+		vary = []string{}
+	)
+
+	for i, v := range args {
+
+		if i < 2 {
+			rs = append(rs, v)
+			continue
+		}
+
+		if strings.HasPrefix(v, "cache_ttl=") {
+			ttl, _ = strconv.ParseInt(v[len("cache_ttl="):], 10, 64)
+			if ttl > pageletCacheTTLMax {
+				ttl = pageletCacheTTLMax
+			}
+		} else if strings.HasPrefix(v, "cache_vary=") {
+			for _, key := range strings.Split(v[len("cache_vary="):], ",") {
+				if key = strings.TrimSpace(key); varNameRE.MatchString(key) {
+					vary = append(vary, key)
+				}
+			}
+		} else {
+			rs = append(rs, v)
+		}
+	}
+
+	sort.Strings(vary)
+
+	return rs, ttl, vary
+}
+
+func pageletCacheKey(data map[string]interface{}, args, vary []string) string {
+
+	keys := []string{"pagelet"}
+
+	if site, ok := data["__site__"].(*api.Site); ok && site != nil {
+		keys = append(keys, site.Name)
+	} else {
+		keys = append(keys, "")
+	}
+
+	var (
+		lang, _ = data["LANG"].(string)
+		url, _  = data["__html_site_url__"].(string)
+	)
+	keys = append(keys, lang, url)
+	keys = append(keys, args...)
+
+	for _, key := range vary {
+		keys = append(keys, key+"="+fmt.Sprintf("%v", data[key]))
+	}
+
+	return PageCacheKey(keys...)
+}
+
+// pageletCacheTags returns the tags of the nodes and terms which are
+// queried by the data action of the pagelet
+func pageletCacheTags(args []string) []string {
+
+	tags := []string{PageCacheTagPagelets()}
+
+	if len(args) < 3 {
+		return tags
+	}
+
+	spec := config.SpecGet(args[0])
+	if spec == nil {
+		return tags
+	}
+
+	for _, action := range spec.Actions {
+
+		if action.Name != args[2] {
+			continue
+		}
+
+		for _, ad := range action.Datax {
+
+			switch ad.Type {
+
+			case "node.list", "node.entry":
+				tags = append(tags, PageCacheTagNodes(spec.Meta.Name, ad.Query.Table))
+				if model, err := config.SpecNodeModel(spec.Meta.Name, ad.Query.Table); err == nil {
+					for _, term := range model.Terms {
+						tags = append(tags, PageCacheTagTerms(spec.Meta.Name, term.Meta.Name))
+					}
+				}
+			}
+		}
+
+		break
+	}
+
+	return tags
+}
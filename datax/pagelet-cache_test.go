@@ -0,0 +1,118 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hooto/hpress/api"
+)
+
+func TestPageletCacheArgs(t *testing.T) {
+
+	for _, v := range []struct {
+		args []string
+		rs   string
+		ttl  int64
+		vary string
+	}{
+		{
+			[]string{"core/general", "v2/footer.tpl", "cache_ttl=600"},
+			"core/general v2/footer.tpl", 600, "",
+		},
+		{
+			[]string{"core/blog", "sidebar.tpl", "list", "cache_ttl=300", "cache_vary=term_tags, term_categories"},
+			"core/blog sidebar.tpl list", 300, "term_categories term_tags",
+		},
+		{
+			[]string{"core/blog", "sidebar.tpl", "list", "cache_ttl=999999", "cache_vary=s_user;x,a b,term_tags"},
+			"core/blog sidebar.tpl list", pageletCacheTTLMax, "term_tags",
+		},
+		{
+			[]string{"cache_ttl=60", "sidebar.tpl", "limit=5"},
+			"cache_ttl=60 sidebar.tpl limit=5", 0, "",
+		},
+	} {
+		rs, ttl, vary := pageletCacheArgs(v.args)
+		if s := strings.Join(rs, " "); s != v.rs {
+			t.Fatalf("Failed on Args %v, expect %s, got %s", v.args, v.rs, s)
+		}
+		if ttl != v.ttl {
+			t.Fatalf("Failed on TTL %v, expect %d, got %d", v.args, v.ttl, ttl)
+		}
+		if s := strings.Join(vary, " "); s != v.vary {
+			t.Fatalf("Failed on Vary %v, expect %s, got %s", v.args, v.vary, s)
+		}
+	}
+}
+
+func TestPageletCacheKey(t *testing.T) {
+
+	var (
+		args = []string{"core/blog", "sidebar.tpl", "list"}
+		vary = []string{"term_tags"}
+		base = map[string]interface{}{
+			"__site__":          &api.Site{Name: "main"},
+			"__html_site_url__": "http://example.com",
+			"LANG":              "en",
+			"term_tags":         "1",
+			"s_user":            "guest",
+		}
+		key = pageletCacheKey(base, args, vary)
+	)
+
+	for k, v := range map[string]interface{}{
+		"__site__":          &api.Site{Name: "blog"},
+		"__html_site_url__": "https://example.com",
+		"LANG":              "zh-CN",
+		"term_tags":         "2",
+	} {
+		data := map[string]interface{}{}
+		for k2, v2 := range base {
+			data[k2] = v2
+		}
+		data[k] = v
+		if pageletCacheKey(data, args, vary) == key {
+			t.Fatalf("Failed on Key, %s is not part of the key", k)
+		}
+	}
+
+	data := map[string]interface{}{}
+	for k, v := range base {
+		data[k] = v
+	}
+	data["term_categories"] = "3"
+	if pageletCacheKey(data, args, vary) != key {
+		t.Fatalf("Failed on Key, term_categories is not a vary key")
+	}
+
+	if pageletCacheKey(base, []string{"core/blog", "sidebar.tpl", "entry"}, vary) == key {
+		t.Fatalf("Failed on Key, the data action is not part of the key")
+	}
+}
+
+func TestPageCacheDataTags(t *testing.T) {
+
+	data := map[string]interface{}{}
+
+	pageCacheDataTagsAdd(data, PageCacheTagPagelets(), "nodes:blog:entry")
+	pageCacheDataTagsAdd(data, PageCacheTagPagelets(), "terms:blog:tags")
+	pageCacheDataTagsAdd(data, PageCacheTagMenu("main"))
+
+	if s := strings.Join(PageCacheDataTags(data), " "); s != "pagelets nodes:blog:entry terms:blog:tags menu:main" {
+		t.Fatalf("Failed on Tags, got %s", s)
+	}
+}
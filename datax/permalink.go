@@ -0,0 +1,140 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"strings"
+
+	"github.com/hooto/hpress/api"
+)
+
+// NodePermalink returns the frontend path of the node, it is built from the
+// first route which renders a node.entry of the model.
+func NodePermalink(mod *api.Spec, table string, node *api.Node) string {
+
+	for i := range mod.Router.Routes {
+
+		route := &mod.Router.Routes[i]
+
+		var entry *api.ActionData
+
+		for _, action := range mod.Actions {
+			if action.Name != route.DataAction {
+				continue
+			}
+			for i, ad := range action.Datax {
+				if ad.Type == "node.entry" && ad.Query.Table == table {
+					entry = &action.Datax[i]
+					break
+				}
+			}
+			break
+		}
+
+		if entry == nil {
+			continue
+		}
+
+		params := map[string]string{}
+		if node.ExtNodeRefer == "" {
+			seg := nodePermalinkSegment(node)
+			params["id"], params[entry.Name+"_id"] = seg, seg
+		}
+
+		if path, ok := route.Build(params); ok {
+			return nodePermalinkJoin(mod, path)
+		}
+	}
+
+	return ""
+}
+
+// NodeReferPermalink returns the frontend path of a node which refers to a
+// parent node, e.g. a page of a doc, it is built from the first route which
+// renders both of the node.entry.
+func NodeReferPermalink(mod *api.Spec, table string, node *api.Node, referTable string, refer *api.Node) string {
+
+	for i := range mod.Router.Routes {
+
+		route := &mod.Router.Routes[i]
+
+		var entry, referEntry *api.ActionData
+
+		for _, action := range mod.Actions {
+			if action.Name != route.DataAction {
+				continue
+			}
+			for i, ad := range action.Datax {
+				if ad.Type != "node.entry" {
+					continue
+				}
+				if ad.Query.Table == table {
+					entry = &action.Datax[i]
+				} else if ad.Query.Table == referTable {
+					referEntry = &action.Datax[i]
+				}
+			}
+			break
+		}
+
+		if entry == nil || referEntry == nil {
+			continue
+		}
+
+		params := map[string]string{
+			entry.Name + "_id":      nodePermalinkSegment(node),
+			referEntry.Name + "_id": nodePermalinkSegment(refer),
+		}
+
+		if path, ok := route.Build(params); ok {
+			return nodePermalinkJoin(mod, path)
+		}
+	}
+
+	return ""
+}
+
+// NodeEntryPermalink returns the frontend path of the node, the published
+// parent of a node which refers to another node is loaded to build the path.
+func NodeEntryPermalink(mod *api.Spec, model *api.NodeModel, node *api.Node) string {
+
+	if model.Extensions.NodeRefer == "" || node.ExtNodeRefer == "" {
+		return NodePermalink(mod, model.Meta.Name, node)
+	}
+
+	q := NewQuery(mod.Meta.Name, model.Extensions.NodeRefer)
+	q.Filter("status", 1)
+	q.Filter("id", node.ExtNodeRefer)
+
+	if refer := q.NodeEntry(); refer.ID != "" {
+		return NodeReferPermalink(mod, model.Meta.Name, node, model.Extensions.NodeRefer, &refer)
+	}
+
+	return ""
+}
+
+func nodePermalinkJoin(mod *api.Spec, path string) string {
+	if path == "/" {
+		return "/" + mod.SrvName
+	}
+	return "/" + mod.SrvName + path
+}
+
+func nodePermalinkSegment(node *api.Node) string {
+	if node.ExtPermalinkName != "" && !strings.HasSuffix(node.SelfLink, ".html") {
+		return node.ExtPermalinkName
+	}
+	return node.ID + ".html"
+}
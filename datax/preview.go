@@ -0,0 +1,167 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/store"
+)
+
+const (
+	PreviewTTLDefault int64 = 3600
+	PreviewTTLMax     int64 = 7 * 86400
+)
+
+var (
+	previewMu  sync.Mutex
+	previewKey []byte
+)
+
+// PreviewClaim is the signed content of a preview token, the nodes of the
+// IDs are rendered whatever their status is.
+type PreviewClaim struct {
+	ModName string   `json:"m"`
+	IDs     []string `json:"i"`
+	Expired int64    `json:"e"`
+}
+
+func (it *PreviewClaim) Allowed(modname, id string) bool {
+	if it == nil || it.ModName != modname {
+		return false
+	}
+	for _, v := range it.IDs {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func previewSecret() ([]byte, error) {
+
+	previewMu.Lock()
+	defer previewMu.Unlock()
+
+	if len(previewKey) > 0 {
+		return previewKey, nil
+	}
+
+	var entry struct {
+		Key string `json:"key"`
+	}
+
+	if rs := store.DataLocal.NewReader(api.NsSysPreviewKey()).Query(); rs.OK() {
+		if err := rs.Decode(&entry); err == nil {
+			if bs, err := hex.DecodeString(entry.Key); err == nil && len(bs) == 32 {
+				previewKey = bs
+				return previewKey, nil
+			}
+		}
+	}
+
+	bs := make([]byte, 32)
+	if _, err := rand.Read(bs); err != nil {
+		return nil, err
+	}
+
+	entry.Key = hex.EncodeToString(bs)
+	if rs := store.DataLocal.NewWriter(api.NsSysPreviewKey(), entry).Commit(); !rs.OK() {
+		return nil, errors.New("DataLocal/Put Error")
+	}
+	previewKey = bs
+
+	return previewKey, nil
+}
+
+func previewSign(key []byte, payload string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// PreviewTokenNew returns a token which expires after ttl seconds
+func PreviewTokenNew(modname string, ids []string, ttl int64) (string, int64, error) {
+
+	if ttl < 1 {
+		ttl = PreviewTTLDefault
+	} else if ttl > PreviewTTLMax {
+		ttl = PreviewTTLMax
+	}
+
+	key, err := previewSecret()
+	if err != nil {
+		return "", 0, err
+	}
+
+	claim := PreviewClaim{
+		ModName: modname,
+		IDs:     ids,
+		Expired: time.Now().Unix() + ttl,
+	}
+
+	bs, err := json.Marshal(claim)
+	if err != nil {
+		return "", 0, err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(bs)
+
+	return payload + "." + previewSign(key, payload), claim.Expired, nil
+}
+
+// PreviewTokenVerify returns the claim of a valid and unexpired token
+func PreviewTokenVerify(token string) (*PreviewClaim, error) {
+
+	n := strings.LastIndex(token, ".")
+	if n < 1 {
+		return nil, errors.New("Invalid Preview Token")
+	}
+
+	key, err := previewSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if !hmac.Equal([]byte(previewSign(key, token[:n])), []byte(token[n+1:])) {
+		return nil, errors.New("Invalid Preview Token")
+	}
+
+	bs, err := base64.RawURLEncoding.DecodeString(token[:n])
+	if err != nil {
+		return nil, errors.New("Invalid Preview Token")
+	}
+
+	var claim PreviewClaim
+	if err := json.Unmarshal(bs, &claim); err != nil {
+		return nil, errors.New("Invalid Preview Token")
+	}
+
+	if claim.Expired < time.Now().Unix() {
+		return nil, errors.New("Preview Token Expired")
+	}
+
+	return &claim, nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreviewToken(t *testing.T) {
+
+	previewMu.Lock()
+	previewKey = []byte("0123456789abcdef0123456789abcdef")
+	previewMu.Unlock()
+
+	token, expired, err := PreviewTokenNew("core/blog", []string{"a1", "b2"}, 60)
+	if err != nil {
+		t.Fatalf("Failed on TokenNew, err %s", err.Error())
+	}
+	if expired <= time.Now().Unix() {
+		t.Fatalf("Failed on TokenNew, expired %d", expired)
+	}
+
+	claim, err := PreviewTokenVerify(token)
+	if err != nil {
+		t.Fatalf("Failed on TokenVerify, err %s", err.Error())
+	}
+	if !claim.Allowed("core/blog", "b2") ||
+		claim.Allowed("core/blog", "c3") ||
+		claim.Allowed("core/gdoc", "a1") {
+		t.Fatalf("Failed on Allowed, claim %v", claim)
+	}
+
+	var (
+		n    = strings.LastIndex(token, ".")
+		sign = token[n+1:]
+		flip = "0"
+	)
+	if sign[0] == '0' {
+		flip = "1"
+	}
+
+	// the payload is changed without signing
+	bs, _ := json.Marshal(PreviewClaim{
+		ModName: "core/blog",
+		IDs:     []string{"a1", "b2", "c3"},
+		Expired: expired,
+	})
+	forged := base64.RawURLEncoding.EncodeToString(bs)
+
+	// the expired claim is signed by the key
+	bs, _ = json.Marshal(PreviewClaim{
+		ModName: "core/blog",
+		IDs:     []string{"a1"},
+		Expired: time.Now().Unix() - 1,
+	})
+	stale := base64.RawURLEncoding.EncodeToString(bs)
+
+	for _, v := range []string{
+		"",
+		"nodot",
+		"." + sign,
+		token[:n] + "." + flip + sign[1:],
+		token[:n] + "." + strings.ToUpper(sign),
+		forged + token[n:],
+		stale + "." + previewSign(previewKey, stale),
+		stale + "." + previewSign([]byte("another key"), stale),
+	} {
+		if _, err := PreviewTokenVerify(v); err == nil {
+			t.Fatalf("Failed on TokenVerify, expect error, got a claim of %s", v)
+		}
+	}
+
+	if _, expired, _ = PreviewTokenNew("core/blog", nil, PreviewTTLMax*2); expired > time.Now().Unix()+PreviewTTLMax {
+		t.Fatalf("Failed on TokenNew, the ttl is not limited")
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+const (
+	redirectChainMax    = 10
+	redirectReferNodeID = "000000000000"
+)
+
+// RedirectPathFilter cleans a local request path, absolute http(s) urls are
+// only allowed as the target of manual entries.
+func RedirectPathFilter(path string, target bool) (string, error) {
+
+	path = strings.TrimSpace(path)
+
+	if target && (strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")) {
+		return path, nil
+	}
+
+	if i := strings.IndexAny(path, "?#"); i >= 0 {
+		path = path[:i]
+	}
+
+	path = filepath.Clean("/" + path)
+	if path == "/" && !target {
+		return "", errors.New("Invalid Redirect Path")
+	}
+
+	return path, nil
+}
+
+func RedirectList() api.RedirectList {
+
+	var (
+		ls     api.RedirectList
+		prefix = api.NsRedirect("")
+		offset = prefix
+	)
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, prefix).
+			LimitNumSet(100).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			var entry api.Redirect
+			if err := v.Decode(&entry); err == nil {
+				ls.Items = append(ls.Items, &entry)
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	ls.Kind = "RedirectList"
+
+	return ls
+}
+
+func RedirectEntry(from string) *api.Redirect {
+
+	var entry api.Redirect
+	if rs := store.DataLocal.NewReader(api.NsRedirect(from)).Query(); rs.OK() {
+		if err := rs.Decode(&entry); err == nil {
+			return &entry
+		}
+	}
+
+	return nil
+}
+
+// RedirectLookup returns the target of the request path, or an empty string
+// if there is no redirect. An exact entry wins over the prefix entries of
+// the parent paths.
+func RedirectLookup(path string) string {
+
+	path, err := RedirectPathFilter(path, false)
+	if err != nil {
+		return ""
+	}
+
+	if entry := RedirectEntry(path); entry != nil {
+		return entry.To
+	}
+
+	for p := filepath.Dir(path); p != "/" && p != "."; p = filepath.Dir(p) {
+		if entry := RedirectEntry(p); entry != nil && entry.Prefix {
+			return redirectJoin(entry.To, path[len(p):])
+		}
+	}
+
+	return ""
+}
+
+func redirectJoin(to, sub string) string {
+	if to == "/" {
+		return sub
+	}
+	return to + sub
+}
+
+// RedirectSet saves the entry and collapses the chains through it, so that
+// every redirect points straight to its final location.
+func RedirectSet(entry *api.Redirect) error {
+
+	var err error
+
+	if entry.From, err = RedirectPathFilter(entry.From, false); err != nil {
+		return err
+	}
+
+	if entry.To, err = RedirectPathFilter(entry.To, true); err != nil {
+		return err
+	}
+
+	if entry.From == entry.To {
+		return errors.New("The target is the same as the source path")
+	}
+
+	if entry.Auto {
+		// the target is a live page again, its old redirect is stale
+		if prev := RedirectEntry(entry.To); prev != nil {
+			if err := RedirectDel(entry.To); err != nil {
+				return err
+			}
+		}
+	} else {
+		for i := 0; !strings.Contains(entry.To, "://"); i++ {
+			next := RedirectLookup(entry.To)
+			if next == "" {
+				break
+			}
+			if next == entry.From || i >= redirectChainMax {
+				return errors.New("Redirect Loop Detected")
+			}
+			entry.To = next
+		}
+	}
+
+	tn := time.Now().Unix()
+	if prev := RedirectEntry(entry.From); prev != nil {
+		entry.Created = prev.Created
+	}
+	if entry.Created == 0 {
+		entry.Created = tn
+	}
+	entry.Updated = tn
+	entry.Kind = "Redirect"
+
+	if rs := store.DataLocal.NewWriter(api.NsRedirect(entry.From), entry).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Put Error")
+	}
+
+	// collapses the chains that end at the source path
+	for _, v := range RedirectList().Items {
+
+		if v.From == entry.From {
+			continue
+		}
+
+		to := ""
+		if v.To == entry.From {
+			to = entry.To
+		} else if entry.Prefix && strings.HasPrefix(v.To, entry.From+"/") {
+			to = redirectJoin(entry.To, v.To[len(entry.From):])
+		}
+
+		if to == "" {
+			continue
+		}
+
+		if to == v.From {
+			RedirectDel(v.From)
+			continue
+		}
+
+		v.To, v.Updated = to, tn
+		store.DataLocal.NewWriter(api.NsRedirect(v.From), v).Commit()
+	}
+
+	return nil
+}
+
+func RedirectDel(from string) error {
+
+	from, err := RedirectPathFilter(from, false)
+	if err != nil {
+		return err
+	}
+
+	if rs := store.DataLocal.NewWriter(api.NsRedirect(from), nil).ModeDeleteSet(true).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Delete Error")
+	}
+
+	return nil
+}
+
+// NodeRedirectSet records the redirect of a node whose permalink has been
+// changed from prev to the current one of the node. If the node is the
+// parent of the nodes which refer to it, e.g. the doc of pages, the paths
+// below the parent are redirected by a prefix entry.
+func NodeRedirectSet(modname, modelid string, node *api.Node, prev string) error {
+
+	mod := config.SpecGet(modname)
+	if mod == nil || prev == node.ExtPermalinkName {
+		return nil
+	}
+
+	model, err := config.SpecNodeModel(modname, modelid)
+	if err != nil {
+		return err
+	}
+
+	prevNode := *node
+	prevNode.ExtPermalinkName = prev
+
+	var (
+		from = NodeEntryPermalink(mod, model, &prevNode)
+		to   = NodeEntryPermalink(mod, model, node)
+	)
+
+	if from != "" && to != "" && from != to {
+		if err := RedirectSet(&api.Redirect{
+			From: from,
+			To:   to,
+			Auto: true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range mod.NodeModels {
+
+		if sub.Extensions.NodeRefer != modelid {
+			continue
+		}
+
+		// any node which refers to the parent, only the path before its
+		// own segment is taken
+		subNode := &api.Node{
+			ID:           redirectReferNodeID,
+			ExtNodeRefer: node.ID,
+		}
+
+		from, to := redirectPrefixes(
+			NodeReferPermalink(mod, sub.Meta.Name, subNode, modelid, &prevNode),
+			NodeReferPermalink(mod, sub.Meta.Name, subNode, modelid, node))
+
+		if from == "" || to == "" || from == to {
+			continue
+		}
+
+		if err := RedirectSet(&api.Redirect{
+			From:   from,
+			To:     to,
+			Prefix: true,
+			Auto:   true,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// redirectPrefixes strips the common trailing segments of the paths
+func redirectPrefixes(from, to string) (string, string) {
+
+	for from != "" && to != "" {
+
+		i, j := strings.LastIndexByte(from, '/'), strings.LastIndexByte(to, '/')
+		if i < 0 || j < 0 || from[i:] != to[j:] {
+			break
+		}
+
+		from, to = from[:i], to[:j]
+	}
+
+	if from == "" || to == "" {
+		return "", ""
+	}
+
+	return from, to
+}
@@ -0,0 +1,73 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"testing"
+)
+
+func TestRedirectPathFilter(t *testing.T) {
+
+	for _, v := range []struct {
+		path   string
+		target bool
+		want   string
+		err    bool
+	}{
+		{"blog/view/a.html?x=1#top", false, "/blog/view/a.html", false},
+		{" /blog//view/../list ", false, "/blog/list", false},
+		{"/", false, "", true},
+		{"?x=1", false, "", true},
+		{"/", true, "/", false},
+		{"https://example.com/a?b=1", true, "https://example.com/a?b=1", false},
+	} {
+		path, err := RedirectPathFilter(v.path, v.target)
+		if (err != nil) != v.err {
+			t.Fatalf("Failed on Filter %q, expect err %v, got %v", v.path, v.err, err)
+		}
+		if path != v.want {
+			t.Fatalf("Failed on Filter %q, expect %s, got %s", v.path, v.want, path)
+		}
+	}
+}
+
+func TestRedirectJoin(t *testing.T) {
+
+	for _, v := range [][3]string{
+		{"/gdoc/view/new", "/intro.html", "/gdoc/view/new/intro.html"},
+		{"/", "/intro.html", "/intro.html"},
+	} {
+		if s := redirectJoin(v[0], v[1]); s != v[2] {
+			t.Fatalf("Failed on Join %s + %s, expect %s, got %s", v[0], v[1], v[2], s)
+		}
+	}
+}
+
+func TestRedirectPrefixes(t *testing.T) {
+
+	for _, v := range [][4]string{
+		{"/gdoc/view/old/000000000000.html", "/gdoc/view/new/000000000000.html", "/gdoc/view/old", "/gdoc/view/new"},
+		{"/gdoc/old/p/000000000000.html", "/gdoc/new/p/000000000000.html", "/gdoc/old", "/gdoc/new"},
+		{"/gdoc/view/old/000000000000.html", "/gdoc/view/old/000000000000.html", "", ""},
+		{"/old/000000000000.html", "/gdoc/000000000000.html", "/old", "/gdoc"},
+		{"", "/gdoc/view/new/000000000000.html", "", ""},
+	} {
+		from, to := redirectPrefixes(v[0], v[1])
+		if from != v[2] || to != v[3] {
+			t.Fatalf("Failed on Prefixes %s > %s, expect %s > %s, got %s > %s",
+				v[0], v[1], v[2], v[3], from, to)
+		}
+	}
+}
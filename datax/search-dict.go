@@ -0,0 +1,350 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hooto/hlog4g/hlog"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+// searchDictSet is the merged synonyms and stopwords of one language and
+// module, the global dictionary of the language is merged with the
+// dictionary of the module.
+type searchDictSet struct {
+	synonyms  map[string]string
+	stopwords map[string]bool
+}
+
+var (
+	searchDictMu          sync.RWMutex
+	searchDictSets        = map[string]*searchDictSet{}
+	searchDictWordsLoaded int32
+)
+
+func SearchDictList() api.SearchDictList {
+
+	var (
+		ls     api.SearchDictList
+		prefix = api.NsSearchDict("", "")
+	)
+
+	// trims the separator of lang and module to scan all of the languages
+	prefix = prefix[:len(prefix)-1]
+	offset := prefix
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, prefix).
+			LimitNumSet(100).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			var dict api.SearchDict
+			if err := v.Decode(&dict); err == nil {
+				ls.Items = append(ls.Items, &dict)
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	ls.Kind = "SearchDictList"
+
+	return ls
+}
+
+func SearchDictEntry(lang, modname string) *api.SearchDict {
+
+	var dict api.SearchDict
+	if rs := store.DataLocal.NewReader(api.NsSearchDict(lang, modname)).Query(); rs.OK() {
+		if err := rs.Decode(&dict); err == nil {
+			return &dict
+		}
+	}
+
+	return nil
+}
+
+// SearchDictSet saves the dictionary, the dictionary is removed if both of
+// the lists are empty. The affected buckets are reindexed.
+func SearchDictSet(dict *api.SearchDict) error {
+
+	dict.Lang = strings.ToLower(dict.Lang)
+
+	langValid := false
+	for _, v := range api.LangArray {
+		if v.Id == dict.Lang {
+			langValid = true
+			break
+		}
+	}
+	if !langValid {
+		return errors.New("Invalid Lang")
+	}
+
+	if dict.ModName != "" {
+		if config.SpecGet(dict.ModName) == nil {
+			return errors.New("Invalid Module Name")
+		}
+	}
+
+	searchDictInit()
+
+	synonyms := [][]string{}
+	for _, group := range dict.Synonyms {
+
+		words := []string{}
+		for _, word := range group {
+			if word = strings.TrimSpace(word); word == "" {
+				continue
+			}
+			// the CJK words are split by the segmentation, they are added to
+			// the dictionary to be indexed as one token
+			if w := searchDictWord(word); w != "" {
+				words = append(words, w)
+				continue
+			}
+			tokens := searchTextTokens(word)
+			if len(tokens) != 1 {
+				return fmt.Errorf("Invalid Synonym (%s), only single words are allowed", word)
+			}
+			words = append(words, tokens[0])
+		}
+
+		if len(words) > 1 {
+			synonyms = append(synonyms, words)
+		}
+	}
+
+	stopwords := []string{}
+	for _, word := range dict.Stopwords {
+		for _, token := range searchTextTokens(word) {
+			stopwords = append(stopwords, token)
+		}
+	}
+	sort.Strings(stopwords)
+
+	dict.Synonyms = synonyms
+	dict.Stopwords = stopwords
+	dict.Updated = time.Now().Unix()
+	dict.Kind = "SearchDict"
+
+	key := api.NsSearchDict(dict.Lang, dict.ModName)
+
+	if len(synonyms) == 0 && len(stopwords) == 0 {
+		if rs := store.DataLocal.NewWriter(key, nil).ModeDeleteSet(true).Commit(); !rs.OK() {
+			return errors.New("DataLocal/Delete Error")
+		}
+	} else if rs := store.DataLocal.NewWriter(key, dict).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Put Error")
+	}
+
+	searchDictMu.Lock()
+	searchDictSets = map[string]*searchDictSet{}
+	searchDictMu.Unlock()
+
+	wordsAdded := false
+	for _, group := range synonyms {
+		for _, word := range group {
+			if searchDictWordAdd(word) {
+				wordsAdded = true
+			}
+		}
+	}
+
+	if searchInited && nodeSearcher != nil {
+		for _, buk := range searchBuckets() {
+			// the new words change the segmentation of all buckets
+			if !wordsAdded && dict.ModName != "" && buk.mod.Meta.Name != dict.ModName {
+				continue
+			}
+			if err := SearchIndexReindex(buk.name); err != nil {
+				hlog.Printf("warn", "search dict reindex %s, err %s", buk.name, err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// searchDictLang returns the language of the dictionary applied to the
+// request language, the default language of the instance if it is empty or
+// unknown.
+func searchDictLang(lang string) string {
+
+	if lang = strings.ToLower(lang); lang != "" {
+		for _, v := range api.LangArray {
+			if v.Id == lang {
+				return lang
+			}
+		}
+	}
+
+	if len(config.Languages) > 0 {
+		return config.Languages[0].Id
+	}
+
+	return api.LangArray[0].Id
+}
+
+func searchDictGet(lang, modname string) *searchDictSet {
+
+	key := lang + ":" + modname
+
+	searchDictMu.RLock()
+	set, ok := searchDictSets[key]
+	searchDictMu.RUnlock()
+
+	if ok {
+		return set
+	}
+
+	set = &searchDictSet{
+		synonyms:  map[string]string{},
+		stopwords: map[string]bool{},
+	}
+
+	for _, dict := range SearchDictList().Items {
+
+		if dict.Lang != lang ||
+			(dict.ModName != "" && dict.ModName != modname) {
+			continue
+		}
+
+		for _, group := range dict.Synonyms {
+			for _, word := range group {
+				if _, ok := set.synonyms[word]; !ok {
+					set.synonyms[word] = group[0]
+				}
+			}
+		}
+
+		for _, word := range dict.Stopwords {
+			set.stopwords[word] = true
+		}
+	}
+
+	searchDictMu.Lock()
+	searchDictSets[key] = set
+	searchDictMu.Unlock()
+
+	return set
+}
+
+// searchDictUserWordsLoad adds the words of the saved synonyms to the
+// dictionary of the segmentation, it is skipped until the local database
+// is opened.
+func searchDictUserWordsLoad() {
+
+	if atomic.LoadInt32(&searchDictWordsLoaded) == 1 || store.DataLocal == nil {
+		return
+	}
+
+	searchDictMu.Lock()
+	defer searchDictMu.Unlock()
+
+	if atomic.LoadInt32(&searchDictWordsLoaded) == 1 {
+		return
+	}
+
+	for _, dict := range SearchDictList().Items {
+		for _, group := range dict.Synonyms {
+			for _, word := range group {
+				searchDictWordAdd(word)
+			}
+		}
+	}
+
+	atomic.StoreInt32(&searchDictWordsLoaded, 1)
+}
+
+// searchDictTokens applies the synonyms and stopwords to the tokens. The
+// indexed text has no language tag, the stopwords of the default language
+// are dropped from it and the synonyms of all languages are added, so that
+// a query replaced by the synonyms of its own language still matches.
+func searchDictTokens(modname, lang string, tokens []string, index bool) []string {
+
+	var (
+		def = searchDictGet(searchDictLang(""), modname)
+		ls  = []string{}
+	)
+
+	if !index {
+
+		set := searchDictGet(searchDictLang(lang), modname)
+		if len(set.synonyms) == 0 && len(set.stopwords) == 0 && len(def.stopwords) == 0 {
+			return tokens
+		}
+
+		for _, token := range tokens {
+			if def.stopwords[token] || set.stopwords[token] {
+				continue
+			}
+			if syn, ok := set.synonyms[token]; ok {
+				token = syn
+			}
+			ls = append(ls, token)
+		}
+
+		if len(ls) == 0 {
+			return tokens
+		}
+
+		return ls
+	}
+
+	sets := []*searchDictSet{}
+	for _, v := range api.LangArray {
+		if set := searchDictGet(v.Id, modname); len(set.synonyms) > 0 {
+			sets = append(sets, set)
+		}
+	}
+
+	if len(sets) == 0 && len(def.stopwords) == 0 {
+		return tokens
+	}
+
+	for _, token := range tokens {
+
+		if def.stopwords[token] {
+			continue
+		}
+
+		ls = append(ls, token)
+
+		syns := map[string]bool{token: true}
+		for _, set := range sets {
+			if syn, ok := set.synonyms[token]; ok && !syns[syn] {
+				syns[syn] = true
+				ls = append(ls, syn)
+			}
+		}
+	}
+
+	return ls
+}
@@ -0,0 +1,190 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+)
+
+type SearchExcerptOptions struct {
+	Length      int
+	BeforeMatch string
+	AfterMatch  string
+}
+
+func searchExcerptOptions() SearchExcerptOptions {
+
+	opts := SearchExcerptOptions{
+		Length:      200,
+		BeforeMatch: config.SysConfigList.FetchString("frontend_search_excerpt_before_match"),
+		AfterMatch:  config.SysConfigList.FetchString("frontend_search_excerpt_after_match"),
+	}
+
+	if n, err := strconv.Atoi(config.SysConfigList.FetchString("frontend_search_excerpt_length")); err == nil && n > 0 {
+		opts.Length = n
+	}
+
+	if opts.BeforeMatch == "" && opts.AfterMatch == "" {
+		opts.BeforeMatch, opts.AfterMatch = "<b>", "</b>"
+	}
+
+	return opts
+}
+
+func searchNodeContent(node *api.Node) string {
+
+	content := ""
+	for _, mf := range node.Fields {
+		if ft := mf.Attrs.Get("format"); len(ft) > 1 {
+			content += mf.Value + "\n"
+		}
+	}
+
+	return strings.Join(strings.Fields(TextHtml2Str(content)), " ")
+}
+
+// searchExcerptsFill builds the excerpts of the items which the search
+// engine did not fill by itself.
+func searchExcerptsFill(items []api.Node, q string) {
+
+	var (
+		opts  = searchExcerptOptions()
+		terms = searchTextTokens(q)
+	)
+
+	for i, v := range items {
+
+		if v.SearchExcerpt != nil {
+			continue
+		}
+
+		items[i].SearchExcerpt = &api.NodeSearchExcerpt{
+			Title:   searchExcerptHighlight(v.Title, terms, opts, 0),
+			Content: searchExcerptHighlight(searchNodeContent(&v), terms, opts, opts.Length),
+		}
+	}
+}
+
+func searchExcerptHighlight(txt string, terms []string, opts SearchExcerptOptions, length int) string {
+
+	var (
+		src   = []rune(txt)
+		norm  = make([]rune, len(src))
+		hits  = make([]bool, len(src))
+		first = -1
+	)
+
+	for i, r := range src {
+		if nr := []rune(SearchTextNormalize(string(r))); len(nr) == 1 {
+			norm[i] = nr[0]
+		} else {
+			norm[i] = r
+		}
+	}
+
+	for _, term := range terms {
+
+		tr := []rune(term)
+		if len(tr) < 1 {
+			continue
+		}
+
+		for i := 0; i+len(tr) <= len(norm); i++ {
+
+			if string(norm[i:i+len(tr)]) != term {
+				continue
+			}
+
+			if !searchIsCjk(tr[0]) {
+				if i > 0 && searchIsWordRune(norm[i-1]) {
+					continue
+				}
+				if i+len(tr) < len(norm) && searchIsWordRune(norm[i+len(tr)]) {
+					continue
+				}
+			}
+
+			for j := i; j < i+len(tr); j++ {
+				hits[j] = true
+			}
+
+			if first == -1 || i < first {
+				first = i
+			}
+		}
+	}
+
+	start, end := 0, len(src)
+	if length > 0 && len(src) > length {
+		if first > length/4 {
+			start = first - length/4
+		}
+		if end = start + length; end > len(src) {
+			end, start = len(src), len(src)-length
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if start > 0 {
+		buf.WriteString("...")
+	}
+
+	for i := start; i < end; i++ {
+		if hits[i] && (i == start || !hits[i-1]) {
+			buf.WriteString(opts.BeforeMatch)
+		}
+		buf.WriteString(html.EscapeString(string(src[i])))
+		if hits[i] && (i+1 == end || !hits[i+1]) {
+			buf.WriteString(opts.AfterMatch)
+		}
+	}
+
+	if end < len(src) {
+		buf.WriteString("...")
+	}
+
+	return buf.String()
+}
+
+func searchIsWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_') && !searchIsCjk(r)
+}
+
+func SearchExcerptPrint(nodeEntry api.Node, name string) template.HTML {
+
+	if nodeEntry.SearchExcerpt == nil {
+		return ""
+	}
+
+	switch name {
+
+	case "title":
+		return template.HTML(nodeEntry.SearchExcerpt.Title)
+
+	case "content":
+		return template.HTML(nodeEntry.SearchExcerpt.Content)
+	}
+
+	return ""
+}
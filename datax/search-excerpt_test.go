@@ -0,0 +1,46 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"testing"
+)
+
+func TestSearchExcerptHighlight(t *testing.T) {
+
+	opts := SearchExcerptOptions{
+		Length:      10,
+		BeforeMatch: "<b>",
+		AfterMatch:  "</b>",
+	}
+
+	for _, v := range []struct {
+		txt    string
+		terms  []string
+		length int
+		want   string
+	}{
+		{"数据库系统的设计", []string{"数据库", "设计"}, 0, "<b>数据库</b>系统的<b>设计</b>"},
+		{"數據庫系統", []string{"数据库"}, 0, "<b>數據庫</b>系統"},
+		{"Go Golang go-lang", []string{"go"}, 0, "<b>Go</b> Golang <b>go</b>-lang"},
+		{"a < b & GO", []string{"go"}, 0, "a &lt; b &amp; <b>GO</b>"},
+		{"没有匹配的内容", []string{"搜索"}, 0, "没有匹配的内容"},
+		{"0123456789集群部署abcdefghij", []string{"集群"}, 10, "...89<b>集群</b>部署abcd..."},
+	} {
+		if s := searchExcerptHighlight(v.txt, v.terms, opts, v.length); s != v.want {
+			t.Fatalf("Failed on Highlight %s, expect %s, got %s", v.txt, v.want, s)
+		}
+	}
+}
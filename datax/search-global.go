@@ -0,0 +1,179 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+)
+
+const (
+	searchGlobalMatchMax int64 = 1000
+)
+
+// NodeGlobalSearch queries every text searchable bucket of all modules and
+// merges the results by relevance, scaled by the per-module weights. The
+// scores of each bucket are normalized by its top score before merging,
+// the raw scores of different buckets are not comparable.
+func NodeGlobalSearch(sq *NodeSearchQuery, offset, limit int64) api.NodeSearchResultList {
+
+	var ls api.NodeSearchResultList
+
+	if !searchInited || nodeSearcher == nil {
+		ls.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Server Not Ready")
+		return ls
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if offset+limit > searchGlobalMatchMax {
+		ls.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Offset Out of Range")
+		return ls
+	}
+
+	var (
+		weights = searchModuleWeights()
+		total   = uint64(0)
+	)
+
+	for _, mod := range config.Modules {
+
+		if mod.Meta.Name == "core/comment" ||
+			(sq.Site != nil && !sq.Site.ModuleAllowed(mod.SrvName)) {
+			continue
+		}
+
+		weight := 1.0
+		if w, ok := weights[mod.Meta.Name]; ok {
+			weight = w
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		for _, model := range mod.NodeModels {
+
+			if !model.Extensions.TextSearch {
+				continue
+			}
+
+			bsq := &NodeSearchQuery{
+				Text:       sq.Text,
+				ModName:    mod.Meta.Name,
+				Lang:       sq.Lang,
+				Terms:      map[string][]uint32{},
+				CreatedMin: sq.CreatedMin,
+				CreatedMax: sq.CreatedMax,
+				nolog:      true,
+				nosuggest:  true,
+			}
+
+			qs := NewQuery(mod.Meta.Name, model.Meta.Name)
+			qs.Limit(offset + limit)
+			qs.Pager = true
+
+			rs := qs.NodeListSearch(bsq)
+			if rs.Error != nil {
+				continue
+			}
+			total += rs.Meta.TotalResults
+
+			scoreMax := int64(0)
+			for _, node := range rs.Items {
+				if node.SearchScore > scoreMax {
+					scoreMax = node.SearchScore
+				}
+			}
+
+			for _, node := range rs.Items {
+				ls.Items = append(ls.Items, api.NodeSearchResult{
+					ModName:   mod.Meta.Name,
+					SrvName:   mod.SrvName,
+					Model:     model.Meta.Name,
+					Permalink: NodePermalink(mod, model.Meta.Name, &node),
+					Score:     searchScoreNormalize(node.SearchScore, scoreMax) * weight,
+					Node:      node,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(ls.Items, func(i, j int) bool {
+		if ls.Items[i].Score != ls.Items[j].Score {
+			return ls.Items[i].Score > ls.Items[j].Score
+		}
+		return ls.Items[i].Node.Created > ls.Items[j].Node.Created
+	})
+
+	if int64(len(ls.Items)) > offset {
+		ls.Items = ls.Items[offset:]
+	} else {
+		ls.Items = nil
+	}
+	if int64(len(ls.Items)) > limit {
+		ls.Items = ls.Items[:limit]
+	}
+
+	if total == 0 {
+		ls.SearchSuggest = searchDidYouMean(sq.Text, searchBuckets())
+	}
+
+	ls.SearchLogID = SearchLogPut(sq.Text, "", "", total)
+
+	ls.Kind = "NodeSearchResultList"
+	ls.Meta.TotalResults = total
+	ls.Meta.StartIndex = uint64(offset)
+	ls.Meta.ItemsPerList = uint64(limit)
+
+	return ls
+}
+
+// searchScoreNormalize scales the score of a bucket into the range of 0 to 1
+// relative to the top score of the bucket.
+func searchScoreNormalize(score, max int64) float64 {
+	if max <= 0 || score <= 0 {
+		return 0
+	}
+	return float64(score) / float64(max)
+}
+
+func searchModuleWeights() map[string]float64 {
+
+	weights := map[string]float64{}
+
+	for _, v := range strings.Split(config.SysConfigList.FetchString("frontend_search_module_weights"), ",") {
+
+		kv := strings.SplitN(strings.TrimSpace(v), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		if w, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+			weights[strings.TrimSpace(kv[0])] = w
+		}
+	}
+
+	return weights
+}
@@ -0,0 +1,298 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hooto/hlog4g/hlog"
+	"github.com/lessos/lessgo/crypto/idhash"
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+var (
+	searchOffline = false
+)
+
+type searchBucket struct {
+	name  string
+	mod   *api.Spec
+	model *api.NodeModel
+}
+
+// SearchIndexOffline setups the search engine without the background
+// index workers, it is used by the command line tools.
+func SearchIndexOffline() error {
+	searchOffline = true
+	return searchEngineInit()
+}
+
+func searchBuckets() []searchBucket {
+
+	ls := []searchBucket{}
+
+	for _, mod := range config.Modules {
+
+		if mod.Meta.Name == "core/comment" {
+			continue
+		}
+
+		for _, model := range mod.NodeModels {
+			if model.Extensions.TextSearch {
+				ls = append(ls, searchBucket{
+					name: fmt.Sprintf("hpn_%s_%s",
+						idhash.HashToHexString([]byte(mod.Meta.Name), 12), model.Meta.Name),
+					mod:   mod,
+					model: model,
+				})
+			}
+		}
+	}
+
+	sort.Slice(ls, func(i, j int) bool {
+		return ls[i].name < ls[j].name
+	})
+
+	return ls
+}
+
+func searchBucketEntry(name string) *searchBucket {
+	for _, v := range searchBuckets() {
+		if v.name == name {
+			return &v
+		}
+	}
+	return nil
+}
+
+func searchCacheIndexed(bukname string) (map[string]bool, error) {
+
+	var (
+		offset = api.NsTextSearchCacheNodeEntry(bukname, "")
+		cutset = api.NsTextSearchCacheNodeEntry(bukname, "")
+		ids    = map[string]bool{}
+	)
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+			LimitNumSet(1000).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			var node api.Node
+			if err := v.Decode(&node); err == nil && node.Status == 1 {
+				ids[node.ID] = true
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	return ids, nil
+}
+
+func SearchIndexStatusList() api.NodeSearchIndexStatusList {
+
+	var ls api.NodeSearchIndexStatusList
+
+	if !searchInited || nodeSearcher == nil {
+		ls.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Server Not Ready")
+		return ls
+	}
+
+	for _, buk := range searchBuckets() {
+
+		status := nodeSearcher.Status(buk.name)
+		status.ModName = buk.mod.Meta.Name
+		status.Model = buk.model.Meta.Name
+
+		var cfgs types.KvPairs
+		if rs := store.DataLocal.NewReader(api.NsSysNodeSearch(buk.name)).Query(); rs.OK() {
+			rs.Decode(&cfgs)
+			status.SyncUpdated, _ = strconv.ParseInt(cfgs.Get("index_updated").String(), 10, 64)
+		}
+
+		ls.Items = append(ls.Items, status)
+	}
+
+	ls.Kind = "NodeSearchIndexStatusList"
+
+	return ls
+}
+
+// SearchIndexReindex resets the sync offset of the bucket so that all rows
+// are pulled again, and asks the engine to rebuild the index.
+func SearchIndexReindex(bukname string) error {
+
+	if !searchInited || nodeSearcher == nil {
+		return errors.New("Server Not Ready")
+	}
+
+	if searchBucketEntry(bukname) == nil {
+		return errors.New("Bucket Not Found")
+	}
+
+	if rs := store.DataLocal.NewWriter(api.NsSysNodeSearch(bukname), nil).
+		ModeDeleteSet(true).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Delete Error")
+	}
+
+	hlog.Printf("info", "search index %s, full reindex", bukname)
+
+	return nodeSearcher.Reindex(bukname)
+}
+
+// SearchIndexCheck compares the indexed node IDs with the published rows
+// of the bucket, the differences are put to the engine again if repair.
+func SearchIndexCheck(bukname string, repair bool) api.NodeSearchIndexCheck {
+
+	rsp := api.NodeSearchIndexCheck{
+		Bucket: bukname,
+	}
+
+	if !searchInited || nodeSearcher == nil {
+		rsp.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Server Not Ready")
+		return rsp
+	}
+
+	buk := searchBucketEntry(bukname)
+	if buk == nil {
+		rsp.Error = types.NewErrorMeta(api.ErrCodeNotFound, "Bucket Not Found")
+		return rsp
+	}
+
+	indexed, err := nodeSearcher.Indexed(bukname)
+	if err != nil {
+		rsp.Error = types.NewErrorMeta(api.ErrCodeInternalError, err.Error())
+		return rsp
+	}
+	rsp.Documents = int64(len(indexed))
+
+	var (
+		limit  int64 = 1000
+		offset int64 = 0
+		rows         = map[string]bool{}
+	)
+
+	for {
+
+		q := store.Data.NewQueryer().Select("id").From(bukname).
+			Order("id ASC").Limit(limit).Offset(offset)
+		q.Where().And("status", 1)
+
+		rs, err := store.Data.Query(q)
+		if err != nil {
+			rsp.Error = types.NewErrorMeta(api.ErrCodeInternalError, err.Error())
+			return rsp
+		}
+
+		for _, v := range rs {
+			rows[v.Field("id").String()] = true
+		}
+
+		if int64(len(rs)) < limit {
+			break
+		}
+		offset += limit
+	}
+	rsp.Rows = int64(len(rows))
+
+	for id := range rows {
+		if !indexed[id] {
+			rsp.Missing = append(rsp.Missing, id)
+		}
+	}
+
+	for id := range indexed {
+		if !rows[id] {
+			rsp.Stale = append(rsp.Stale, id)
+		}
+	}
+
+	sort.Strings(rsp.Missing)
+	sort.Strings(rsp.Stale)
+
+	rsp.Kind = "NodeSearchIndexCheck"
+
+	if !repair {
+		return rsp
+	}
+
+	modCache := searchModuleCacheRefresh(buk.mod)
+	nodeSearcher.ModelSet(bukname, buk.model)
+
+	for i := 0; i < len(rsp.Missing); i += 100 {
+
+		ids := []interface{}{}
+		for j := i; j < len(rsp.Missing) && j < i+100; j++ {
+			ids = append(ids, rsp.Missing[j])
+		}
+
+		q := store.Data.NewQueryer().From(bukname).Limit(int64(len(ids)))
+		q.Where().And("id.in", ids...)
+
+		rs, err := store.Data.Query(q)
+		if err != nil {
+			rsp.Error = types.NewErrorMeta(api.ErrCodeInternalError, err.Error())
+			return rsp
+		}
+
+		for _, v := range rs {
+			item := searchNodeEntry(buk.model, modCache, v)
+			searchSuggestPut(bukname, &item)
+			if err := nodeSearcher.Put(bukname, item); err == nil {
+				rsp.Repaired += 1
+			}
+		}
+	}
+
+	for _, id := range rsp.Stale {
+
+		node := api.Node{
+			ID: id,
+		}
+
+		if rs := store.DataLocal.NewReader(api.NsTextSearchCacheNodeEntry(bukname, id)).Query(); rs.OK() {
+			rs.Decode(&node)
+		}
+		node.Status = 0
+
+		searchSuggestPut(bukname, &node)
+		if err := nodeSearcher.Put(bukname, node); err == nil {
+			rsp.Repaired += 1
+		}
+	}
+
+	if rsp.Repaired > 0 {
+		hlog.Printf("info", "search index %s, repaired %d", bukname, rsp.Repaired)
+		// without the index workers the puts only reach the node caches,
+		// the engine rebuilds the index from them on next start
+		if searchOffline {
+			nodeSearcher.Reindex(bukname)
+		}
+	}
+
+	return rsp
+}
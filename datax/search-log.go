@@ -0,0 +1,222 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+var (
+	searchLogSeq   uint32
+	searchLogIdReg = regexp.MustCompile("^[0-9a-f]{20}$")
+)
+
+const (
+	searchLogScanMax = 100000
+)
+
+func searchLogRetention() int64 {
+	days, _ := strconv.ParseInt(config.SysConfigList.FetchString("search_log_retention_days"), 10, 64)
+	if days < 0 {
+		days = 0
+	}
+	return days * 86400 * 1000
+}
+
+// SearchLogPut records a search query, it returns the log id which can be
+// passed back by the clicked result links.
+func SearchLogPut(q, modname, model string, results uint64) string {
+
+	ttl := searchLogRetention()
+	if ttl < 1 || q == "" {
+		return ""
+	}
+
+	tn := time.Now()
+
+	entry := api.SearchLogEntry{
+		ID: fmt.Sprintf("%016x%04x",
+			tn.UnixNano(), atomic.AddUint32(&searchLogSeq, 1)&0xffff),
+		Query:   q,
+		ModName: modname,
+		Model:   model,
+		Results: results,
+		Created: tn.Unix(),
+	}
+
+	if rs := store.DataLocal.NewWriter(api.NsSearchLog(entry.ID), entry).
+		ExpireSet(ttl).Commit(); !rs.OK() {
+		return ""
+	}
+
+	return entry.ID
+}
+
+// SearchLogClick records the result opened from the search results.
+func SearchLogClick(id, modname, nodeId string) {
+
+	if !searchLogIdReg.MatchString(id) {
+		return
+	}
+
+	var entry api.SearchLogEntry
+	if rs := store.DataLocal.NewReader(api.NsSearchLog(id)).Query(); !rs.OK() || rs.Decode(&entry) != nil {
+		return
+	}
+
+	ttl := searchLogRetention() - (time.Now().Unix()-entry.Created)*1000
+	if ttl < 1 {
+		return
+	}
+
+	if entry.ModName == "" {
+		entry.ModName = modname
+	}
+	entry.Clicked = nodeId
+	entry.Clicks += 1
+
+	store.DataLocal.NewWriter(api.NsSearchLog(id), entry).ExpireSet(ttl).Commit()
+}
+
+// searchLogSinceKey returns the scan offset of the logs created since the
+// unix time, the log ids are prefixed by the hex creation time in nanoseconds.
+func searchLogSinceKey(since int64) []byte {
+	return api.NsSearchLog(fmt.Sprintf("%016x", since*int64(time.Second)))
+}
+
+// SearchLogStatsQuery aggregates the search logs of the last days, the
+// queries are grouped by the normalized query text.
+func SearchLogStatsQuery(modname string, days, limit int) api.SearchLogStats {
+
+	if days < 1 {
+		days = 7
+	}
+	if limit < 1 {
+		limit = 20
+	}
+
+	var (
+		rsp = api.SearchLogStats{
+			Since: time.Now().Unix() - int64(days)*86400,
+		}
+		offset  = searchLogSinceKey(rsp.Since)
+		cutset  = api.NsSearchLog("")
+		num     = 0
+		queries = map[string]*api.SearchLogQueryStats{}
+		modules = map[string]*api.SearchLogModuleStats{}
+	)
+
+	for num < searchLogScanMax {
+
+		ls := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+			LimitNumSet(1000).Query()
+
+		for _, v := range ls.Items {
+
+			offset = v.Meta.Key
+			num += 1
+
+			var entry api.SearchLogEntry
+			if err := v.Decode(&entry); err != nil || entry.Created < rsp.Since {
+				continue
+			}
+
+			if modname != "" && entry.ModName != modname {
+				continue
+			}
+
+			rsp.Searches += 1
+
+			qkey := searchSuggestNormalize(entry.Query)
+
+			qs, ok := queries[qkey]
+			if !ok {
+				qs = &api.SearchLogQueryStats{
+					Query: entry.Query,
+				}
+				queries[qkey] = qs
+			}
+			qs.Count += 1
+			qs.Clicks += entry.Clicks
+			qs.Results = entry.Results
+
+			ms, ok := modules[entry.ModName]
+			if !ok {
+				ms = &api.SearchLogModuleStats{
+					ModName: entry.ModName,
+				}
+				modules[entry.ModName] = ms
+			}
+			ms.Searches += 1
+			if entry.Results == 0 {
+				ms.Zeros += 1
+			}
+			if entry.Clicks > 0 {
+				ms.Clicked += 1
+			}
+		}
+
+		if !ls.Next {
+			break
+		}
+	}
+
+	for _, v := range queries {
+		rsp.TopQueries = append(rsp.TopQueries, v)
+		if v.Results == 0 {
+			rsp.ZeroQueries = append(rsp.ZeroQueries, v)
+		}
+	}
+
+	for _, ls := range [][]*api.SearchLogQueryStats{rsp.TopQueries, rsp.ZeroQueries} {
+		sort.Slice(ls, func(i, j int) bool {
+			if ls[i].Count != ls[j].Count {
+				return ls[i].Count > ls[j].Count
+			}
+			return ls[i].Query < ls[j].Query
+		})
+	}
+
+	if len(rsp.TopQueries) > limit {
+		rsp.TopQueries = rsp.TopQueries[:limit]
+	}
+	if len(rsp.ZeroQueries) > limit {
+		rsp.ZeroQueries = rsp.ZeroQueries[:limit]
+	}
+
+	for _, v := range modules {
+		if v.Searches > 0 {
+			v.ClickRate = float64(v.Clicked) / float64(v.Searches)
+		}
+		rsp.Modules = append(rsp.Modules, v)
+	}
+
+	sort.Slice(rsp.Modules, func(i, j int) bool {
+		return rsp.Modules[i].Searches > rsp.Modules[j].Searches
+	})
+
+	rsp.Kind = "SearchLogStats"
+
+	return rsp
+}
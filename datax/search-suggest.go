@@ -0,0 +1,436 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/store"
+)
+
+const (
+	searchSuggestTypeTitle    = "title"
+	searchSuggestTypeTag      = "tag"
+	searchSuggestTypeCategory = "category"
+	searchSuggestTypeWord     = "word"
+	searchSuggestLimit        = 10
+	searchSuggestScanMax      = 2000
+)
+
+type searchSuggestEntry struct {
+	Text  string `json:"text"`
+	Type  string `json:"type"`
+	ID    string `json:"id,omitempty"`
+	Count int64  `json:"count,omitempty"` // the nodes which refer to the entry
+}
+
+var (
+	searchSuggestMu sync.Mutex
+)
+
+// the suggest vocabulary is keyed by the normalized text, so that the
+// completions of a prefix can be fetched by a key range scan.
+func searchSuggestKey(bukname, text, ref string) []byte {
+	return api.NsTextSearchSuggest(bukname, searchSuggestNormalize(text)+":"+ref)
+}
+
+func searchSuggestNormalize(text string) string {
+	text = strings.Replace(SearchTextNormalize(text), ":", " ", -1)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// searchSuggestPut updates the suggest entries of the node. An entry may be
+// shared by many nodes, e.g. a tag or a word, it counts the nodes which refer
+// to it and is removed when the last one is updated, unpublished or deleted.
+// The keys referred by each node are kept to find the entries to release.
+func searchSuggestPut(bukname string, node *api.Node) {
+
+	searchSuggestMu.Lock()
+	defer searchSuggestMu.Unlock()
+
+	var (
+		refsKey  = api.NsTextSearchSuggestRefs(bukname, node.ID)
+		prevKeys []string
+	)
+
+	if rs := store.DataLocal.NewReader(refsKey).Query(); rs.OK() {
+		rs.Decode(&prevKeys)
+	} else {
+		// the node is put before the references were counted
+		var prev api.Node
+		if rs := store.DataLocal.NewReader(api.NsTextSearchCacheNodeEntry(bukname, node.ID)).Query(); rs.OK() {
+			rs.Decode(&prev)
+		}
+		if prev.ID != "" && prev.Status == 1 {
+			for key := range searchSuggestEntries(bukname, &prev) {
+				prevKeys = append(prevKeys, key)
+			}
+		}
+	}
+
+	entries := map[string]searchSuggestEntry{}
+	if node.Status == 1 {
+		entries = searchSuggestEntries(bukname, node)
+	}
+
+	prevSets := map[string]bool{}
+	for _, key := range prevKeys {
+		prevSets[key] = true
+		if _, ok := entries[key]; !ok {
+			searchSuggestRelease(key)
+		}
+	}
+
+	keys := []string{}
+	for key, entry := range entries {
+		searchSuggestRetain(key, entry, !prevSets[key])
+		keys = append(keys, key)
+	}
+
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		store.DataLocal.NewWriter(refsKey, keys).Commit()
+	} else if len(prevKeys) > 0 {
+		store.DataLocal.NewWriter(refsKey, nil).ModeDeleteSet(true).Commit()
+	}
+}
+
+// searchSuggestEntries returns the suggest entries of the title, terms and
+// words of the node
+func searchSuggestEntries(bukname string, node *api.Node) map[string]searchSuggestEntry {
+
+	entries := map[string]searchSuggestEntry{}
+
+	if node.Title != "" {
+		entries[string(searchSuggestKey(bukname, node.Title, "n:"+node.ID))] = searchSuggestEntry{
+			Text: node.Title,
+			Type: searchSuggestTypeTitle,
+			ID:   node.ID,
+		}
+	}
+
+	words := searchTextTokens(node.Title)
+
+	for _, nt := range node.Terms {
+
+		for _, v := range nt.Items {
+
+			if v.Title == "" {
+				continue
+			}
+
+			entry := searchSuggestEntry{
+				Text: v.Title,
+				Type: searchSuggestTypeTag,
+			}
+			if nt.Type == api.TermTaxonomy {
+				entry.Type = searchSuggestTypeCategory
+			}
+
+			entries[string(searchSuggestKey(bukname, v.Title, "t:"+nt.Name))] = entry
+
+			words = append(words, searchTextTokens(v.Title)...)
+		}
+	}
+
+	for _, word := range words {
+		if searchSuggestWordValid(word) {
+			entries[string(searchSuggestKey(bukname, word, "w"))] = searchSuggestEntry{
+				Text: word,
+				Type: searchSuggestTypeWord,
+			}
+		}
+	}
+
+	return entries
+}
+
+// searchSuggestRetain saves the entry, the count of the entry is increased
+// if the node did not refer to it before.
+func searchSuggestRetain(key string, entry searchSuggestEntry, ref bool) {
+
+	var prev searchSuggestEntry
+	if rs := store.DataLocal.NewReader([]byte(key)).Query(); rs.OK() && rs.Decode(&prev) == nil {
+		entry.Count = prev.Count
+		if entry.Count < 1 {
+			entry.Count = 1
+		}
+		if ref {
+			entry.Count += 1
+		}
+	} else {
+		entry.Count = 1
+	}
+
+	store.DataLocal.NewWriter([]byte(key), entry).Commit()
+}
+
+// searchSuggestRelease decreases the count of the entry, and removes it if
+// there are no more nodes which refer to it.
+func searchSuggestRelease(key string) {
+
+	var entry searchSuggestEntry
+	if rs := store.DataLocal.NewReader([]byte(key)).Query(); !rs.OK() || rs.Decode(&entry) != nil {
+		return
+	}
+
+	if entry.Count -= 1; entry.Count > 0 {
+		store.DataLocal.NewWriter([]byte(key), entry).Commit()
+	} else {
+		store.DataLocal.NewWriter([]byte(key), nil).ModeDeleteSet(true).Commit()
+	}
+}
+
+// only the latin words can be corrected by the edit distance
+func searchSuggestWordValid(word string) bool {
+
+	if utf8.RuneCountInString(word) < 3 {
+		return false
+	}
+
+	for _, c := range word {
+		if !unicode.IsLetter(c) || searchIsCjk(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NodeSearchSuggest returns the completions of the query, and a corrected
+// query if there are no completions. The buckets can be narrowed by the
+// module and model name.
+func NodeSearchSuggest(q, modname, model string) api.NodeSearchSuggestList {
+
+	buckets := []searchBucket{}
+
+	for _, buk := range searchBuckets() {
+		if (modname == "" || buk.mod.Meta.Name == modname) &&
+			(model == "" || buk.model.Meta.Name == model) {
+			buckets = append(buckets, buk)
+		}
+	}
+
+	ls := searchSuggestList(q, buckets)
+	if len(ls.Items) == 0 {
+		ls.DidYouMean = searchDidYouMean(q, buckets)
+	}
+
+	return ls
+}
+
+// searchSuggestList returns the prefix completions of the titles, tags and
+// categories in the buckets.
+func searchSuggestList(q string, buckets []searchBucket) api.NodeSearchSuggestList {
+
+	var (
+		ls     api.NodeSearchSuggestList
+		prefix = searchSuggestNormalize(q)
+		sets   = map[string]bool{}
+	)
+
+	ls.Kind = "NodeSearchSuggestList"
+
+	if utf8.RuneCountInString(prefix) < 2 {
+		return ls
+	}
+
+	for _, buk := range buckets {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(
+			api.NsTextSearchSuggest(buk.name, prefix),
+			api.NsTextSearchSuggest(buk.name, prefix)).LimitNumSet(100).Query()
+
+		for _, v := range rs.Items {
+
+			var entry searchSuggestEntry
+			if err := v.Decode(&entry); err != nil || entry.Type == searchSuggestTypeWord {
+				continue
+			}
+
+			key := entry.Type + ":" + strings.ToLower(entry.Text)
+			if sets[key] {
+				continue
+			}
+			sets[key] = true
+
+			item := api.NodeSearchSuggest{
+				Text:    entry.Text,
+				Type:    entry.Type,
+				ModName: buk.mod.Meta.Name,
+				Model:   buk.model.Meta.Name,
+			}
+
+			if entry.ID != "" {
+				item.Permalink = NodePermalink(buk.mod, buk.model.Meta.Name, &api.Node{ID: entry.ID})
+			}
+
+			ls.Items = append(ls.Items, item)
+
+			if len(ls.Items) >= searchSuggestLimit {
+				return ls
+			}
+		}
+	}
+
+	return ls
+}
+
+// searchDidYouMean returns a corrected query if some of the words of the
+// query are not found in any of the buckets, or an empty string.
+func searchDidYouMean(q string, buckets []searchBucket) string {
+
+	if !searchInited || nodeSearcher == nil {
+		return ""
+	}
+
+	docs := map[string]int64{}
+
+	for _, buk := range buckets {
+		kws, err := nodeSearcher.Keywords(buk.name, q)
+		if err != nil {
+			continue
+		}
+		for k, n := range kws {
+			docs[k] += n
+		}
+	}
+
+	var (
+		words   = strings.Fields(SearchTextNormalize(q))
+		changed = false
+	)
+
+	for i, word := range words {
+
+		word = strings.TrimFunc(word, func(c rune) bool {
+			return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+		})
+
+		if !searchSuggestWordValid(word) || docs[word] > 0 {
+			continue
+		}
+
+		if fix := searchSuggestCorrect(word, buckets); fix != "" && fix != word {
+			words[i] = fix
+			changed = true
+		}
+	}
+
+	if changed {
+		return strings.Join(words, " ")
+	}
+
+	return ""
+}
+
+func searchSuggestCorrect(word string, buckets []searchBucket) string {
+
+	var (
+		rs     = []rune(word)
+		maxDis = 1
+		hit    = ""
+		hitDis = -1
+	)
+
+	if len(rs) > 4 {
+		maxDis = 2
+	}
+
+	for _, buk := range buckets {
+
+		var (
+			offset = api.NsTextSearchSuggest(buk.name, string(rs[0]))
+			cutset = api.NsTextSearchSuggest(buk.name, string(rs[0]))
+			num    = 0
+		)
+
+		for num < searchSuggestScanMax {
+
+			ls := store.DataLocal.NewReader(nil).KeyRangeSet(offset, cutset).
+				LimitNumSet(500).Query()
+
+			for _, v := range ls.Items {
+
+				offset = v.Meta.Key
+				num += 1
+
+				if !strings.HasSuffix(string(v.Meta.Key), ":w") {
+					continue
+				}
+
+				var entry searchSuggestEntry
+				if err := v.Decode(&entry); err != nil {
+					continue
+				}
+
+				d := searchEditDistance(rs, []rune(entry.Text))
+				if d <= maxDis && (hitDis < 0 || d < hitDis) {
+					hit, hitDis = entry.Text, d
+				}
+			}
+
+			if !ls.Next {
+				break
+			}
+		}
+	}
+
+	return hit
+}
+
+func searchEditDistance(a, b []rune) int {
+
+	if len(a) == 0 {
+		return len(b)
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = prev[j-1] + cost
+			if v := prev[j] + 1; v < curr[j] {
+				curr[j] = v
+			}
+			if v := curr[j-1] + 1; v < curr[j] {
+				curr[j] = v
+			}
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
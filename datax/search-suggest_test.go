@@ -0,0 +1,44 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"testing"
+)
+
+func TestSearchEditDistance(t *testing.T) {
+
+	for _, v := range []struct {
+		a, b string
+		n    int
+	}{
+		{"", "", 0},
+		{"", "go", 2},
+		{"go", "", 2},
+		{"golang", "golang", 0},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"databse", "database", 1},
+		{"数据库", "数据仓库", 1},
+		{"搜索引擎", "搜素引擎", 1},
+	} {
+		if n := searchEditDistance([]rune(v.a), []rune(v.b)); n != v.n {
+			t.Fatalf("Failed on EditDistance %s %s, expect %d, got %d", v.a, v.b, v.n, n)
+		}
+		if n := searchEditDistance([]rune(v.b), []rune(v.a)); n != v.n {
+			t.Fatalf("Failed on EditDistance %s %s, expect %d, got %d", v.b, v.a, v.n, n)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+// searchHansFolds lists the Traditional to Simplified Chinese character
+// pairs folded by SearchTextNormalize.
+const searchHansFolds = `
+萬万 與与 專专 業业 東东 絲丝 兩两 嚴严 喪丧 個个 豐丰 臨临 為为 爲为 麗丽 舉举
+義义 烏乌 樂乐 喬乔 習习 鄉乡 書书 買买 亂乱 爭争 於于 虧亏 雲云 亞亚 產产 畝亩
+親亲 億亿 僅仅 從从 侖仑 倉仓 儀仪 們们 價价 眾众 衆众 優优 會会 傘伞 偉伟 傳传
+傷伤 倫伦 偽伪 體体 餘余 傭佣 來来 侶侣 俠侠 側侧 僑侨 儂侬 債债 傾倾 儲储 兒儿
+黨党 蘭兰 關关 興兴 養养 獸兽 內内 岡冈 冊册 寫写 軍军 農农 馮冯 衝冲 決决 況况
+凍冻 淨净 準准 涼凉 減减 湊凑 幾几 鳳凤 憑凭 凱凯 擊击 鑿凿 劃划 劉刘 則则 剛刚
+創创 刪删 別别 劑剂 剝剥 劇剧 勸劝 辦办 務务 動动 勵励 勁劲 勞劳 勢势 勛勋 勻匀
+區区 醫医 華华 協协 單单 賣卖 盧卢 衛卫 卻却 廠厂 廳厅 歷历 曆历 厲厉 壓压 厭厌
+廁厕 縣县 參参 雙双 發发 髮发 變变 敘叙 疊叠 葉叶 號号 嘆叹 後后 嚇吓 呂吕 嗎吗
+噸吨 聽听 啟启 啓启 吳吴 嘔呕 員员 嗚呜 詠咏 團团 園园 圍围 圖图 國国 圓圆 聖圣
+場场 壞坏 塊块 堅坚 壇坛 壩坝 墳坟 墜坠 壘垒 壟垄 執执 報报 塗涂 塢坞 堯尧 牆墙
+壯壮 聲声 殼壳 壺壶 處处 備备 複复 復复 夠够 頭头 誇夸 夾夹 奪夺 奮奋 獎奖 婦妇
+媽妈 嫵妩 婁娄 孫孙 學学 寧宁 寶宝 實实 寵宠 審审 憲宪 宮宫 寬宽 賓宾 對对 尋寻
+導导 將将 爾尔 塵尘 嘗尝 層层 屬属 歲岁 豈岂 島岛 嶺岭 崗岗 幣币 師师 帳帐 帶带
+幫帮 廣广 莊庄 慶庆 廬庐 庫库 應应 廟庙 廢废 開开 異异 棄弃 張张 彌弥 彎弯 強强
+歸归 當当 錄录 彥彦 徹彻 徑径 憶忆 懷怀 態态 懇恳 戀恋 惡恶 惱恼 悅悦 懸悬 驚惊
+慘惨 慚惭 慣惯 憤愤 願愿 戲戏 戰战 戶户 撲扑 擴扩 掃扫 揚扬 擾扰 撫抚 搶抢 護护
+擔担 擬拟 擁拥 揀拣 擇择 掛挂 擠挤 揮挥 撈捞 損损 撿捡 換换 據据 擄掳 攜携 攝摄
+擺摆 搖摇 數数 敵敌 斂敛 斷断 無无 舊旧 時时 曠旷 晝昼 顯显 晉晋 曬晒 曉晓 暈晕
+暫暂 術术 機机 殺杀 雜杂 權权 條条 楊杨 極极 構构 槍枪 櫃柜 標标 棧栈 樣样 檔档
+橋桥 樹树 橫横 檢检 歡欢 殘残 毀毁 毆殴 氣气 漢汉 湯汤 溝沟 沒没 滬沪 灘滩 潔洁
+灑洒 澆浇 濁浊 測测 濟济 瀏浏 渾浑 濃浓 濤涛 澇涝 潤润 漲涨 淚泪 滅灭 燈灯 靈灵
+災灾 爐炉 點点 煉炼 爛烂 煩烦 燒烧 熱热 愛爱 牽牵 犧牺 狀状 猶犹 獨独 獄狱 獵猎
+貓猫 獻献 環环 現现 瑪玛 璽玺 瓊琼 畫画 暢畅 療疗 瘋疯 癢痒 盜盗 盡尽 監监 盤盘
+睜睁 瞞瞒 礦矿 碼码 磚砖 確确 礎础 禮礼 禍祸 離离 種种 積积 稱称 穩稳 窮穷 竊窃
+競竞 筆笔 節节 範范 築筑 簡简 籃篮 類类 糧粮 緊紧 紅红 約约 級级 紀纪 純纯 紙纸
+紛纷 組组 細细 終终 經经 結结 給给 絕绝 統统 絡络 繼继 續续 線线 綫线 練练 織织
+緒绪 編编 總总 網网 綠绿 維维 綜综 緣缘 縮缩 績绩 鏈链 罷罢 羅罗 聯联 聰聪 職职
+肅肃 腦脑 腳脚 膽胆 臉脸 艦舰 艱艰 藝艺 蘇苏 蘋苹 莖茎 薦荐 藥药 蓋盖 蟲虫 蝦虾
+補补 裝装 製制 襯衬 襲袭 見见 規规 視视 覺觉 覽览 觀观 觸触 計计 訂订 認认 討讨
+讓让 訓训 議议 記记 講讲 許许 論论 設设 訪访 證证 評评 識识 詞词 試试 詩诗 話话
+該该 詳详 語语 誤误 說说 請请 讀读 課课 誰谁 調调 談谈 謝谢 謎谜 謀谋 譜谱 譯译
+貝贝 負负 財财 責责 貨货 質质 貪贪 貧贫 購购 貯贮 費费 資资 賊贼 賞赏 賴赖 贈赠
+贊赞 趕赶 趙赵 躍跃 車车 軟软 轉转 輪轮 輕轻 載载 較较 輸输 辭辞 邊边 遼辽 達达
+遷迁 過过 運运 還还 這这 進进 遠远 違违 連连 遲迟 適适 選选 遺遗 鄧邓 鄭郑 醜丑
+釋释 鐘钟 鍾钟 針针 釘钉 鋼钢 錢钱 鐵铁 銀银 錯错 鍵键 鏡镜 鑑鉴 鑒鉴 長长 門门
+閃闪 問问 間间 閱阅 闊阔 隊队 陽阳 陰阴 際际 陸陆 險险 隨随 難难 雞鸡 電电 霧雾
+靜静 麵面 韓韩 韌韧 頁页 項项 順顺 須须 預预 領领 頻频 題题 額额 顏颜 風风 飛飞
+飯饭 飲饮 館馆 馬马 驗验 騎骑 驅驱 鬥斗 魚鱼 鳥鸟 鳴鸣 黃黄 齊齐 齒齿 龍龙 龜龟
+臺台 颱台 檯台 裡里 裏里 幹干 鬆松 嚮向 係系 繫系 麼么 峯峰 佈布 錶表 隻只
+`
+
+// searchDictWords is the built-in dictionary of common Simplified Chinese
+// words, it can be extended by etc/search_dict.txt with one word per line.
+const searchDictWords = `
+中国 中文 英文 世界 国家 政府 社会 经济 文化 历史 教育 科学 技术 科技 研究 发展
+我们 你们 他们 她们 它们 自己 大家 什么 怎么 怎样 如何 为什么 可以 可能 应该 需要
+没有 已经 因为 所以 但是 如果 虽然 而且 或者 还是 这个 那个 这些 那些 一个 一些
+时间 时候 今天 明天 昨天 现在 以前 以后 之前 之后 开始 结束 问题 方法 方式 解决
+方案 实现 功能 项目 团队 产品 市场 公司 企业 用户 客户 服务 服务器 系统 操作系统
+操作 管理 管理员 配置 安装 部署 升级 更新 版本 发布 下载 上传 文件 文件夹 目录
+路径 文档 数据 数据库 数据结构 结构 存储 缓存 索引 搜索 搜索引擎 引擎 查询 检索
+网络 网站 网页 页面 链接 地址 域名 协议 接口 请求 响应 浏览器 客户端 服务端 前端
+后端 开发 开发者 程序 程序员 编程 语言 编程语言 代码 源代码 开源 软件 硬件 计算
+计算机 电脑 手机 移动 应用 应用程序 平台 框架 组件 模块 插件 工具 命令 命令行 脚本
+函数 变量 参数 对象 类型 字符 字符串 数组 列表 集合 算法 模型 性能 优化 测试 调试
+错误 异常 日志 监控 安全 权限 认证 授权 密码 加密 解密 证书 容器 镜像 集群 节点
+分布式 负载 均衡 负载均衡 虚拟 虚拟机 云计算 云服务 人工智能 机器 学习 机器学习
+深度学习 神经网络 训练 推理 设计 架构 博客 文章 内容 分类 标签 评论 作者 标题
+摘要 正文 图片 视频 音频 新闻 教程 入门 指南 手册 帮助 介绍 说明 示例 例子 简单
+快速 高效 稳定 可靠 免费 支持 使用 利用 选择 设置 修改 删除 添加 创建 编辑 保存
+打开 关闭 运行 启动 停止 重启 连接 断开 登录 注册 退出 首页 关于 联系 中心 信息
+北京 上海 广州 深圳 香港 台湾 日本 美国 欧洲 中华 人民 共和国 中华人民共和国
+`
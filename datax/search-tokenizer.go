@@ -0,0 +1,317 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"bufio"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/hooto/hpress/config"
+)
+
+// SearchTokenizer splits the text of nodes and queries into the terms
+// used by the search engines, both sides must use the same tokenizer.
+type SearchTokenizer interface {
+	Tokens(txt string) []string
+}
+
+const (
+	SearchTokenizerDefault = "cjk"
+	searchDictWordMax      = 8
+)
+
+var (
+	searchTokenizerMu sync.RWMutex
+	searchTokenizers  = map[string]SearchTokenizer{
+		SearchTokenizerDefault: &searchCjkTokenizer{},
+	}
+	searchDictOnce sync.Once
+	searchDictRw   sync.RWMutex
+	searchDict     = map[string]bool{}
+	searchFoldKana = map[rune]rune{}
+	searchFoldHans = map[rune]rune{}
+)
+
+func SearchTokenizerRegister(name string, tk SearchTokenizer) {
+	searchTokenizerMu.Lock()
+	defer searchTokenizerMu.Unlock()
+	searchTokenizers[name] = tk
+}
+
+func searchTokenizerGet() SearchTokenizer {
+
+	searchTokenizerMu.RLock()
+	defer searchTokenizerMu.RUnlock()
+
+	if tk, ok := searchTokenizers[config.Config.SearchTokenizer]; ok {
+		return tk
+	}
+
+	return searchTokenizers[SearchTokenizerDefault]
+}
+
+func searchTextTokens(txt string) []string {
+	return searchTokenizerGet().Tokens(txt)
+}
+
+func searchDictInit() {
+
+	searchDictOnce.Do(func() {
+
+		for _, w := range strings.Fields(searchDictWords) {
+			searchDict[w] = true
+		}
+
+		if fp, err := os.Open(config.Prefix + "/etc/search_dict.txt"); err == nil {
+			scan := bufio.NewScanner(fp)
+			for scan.Scan() {
+				if w := SearchTextNormalize(strings.TrimSpace(scan.Text())); w != "" {
+					searchDict[w] = true
+				}
+			}
+			fp.Close()
+		}
+	})
+
+	searchDictUserWordsLoad()
+}
+
+// searchDictWord returns the normalized form of a dictionary word, or an
+// empty string if the word is not a run of CJK characters.
+func searchDictWord(word string) string {
+
+	rs := []rune(SearchTextNormalize(word))
+	if len(rs) < 2 || len(rs) > searchDictWordMax {
+		return ""
+	}
+	for _, r := range rs {
+		if !searchIsCjk(r) {
+			return ""
+		}
+	}
+
+	return string(rs)
+}
+
+// searchDictWordAdd adds a word to the dictionary of the segmentation, it
+// returns true if the word is new.
+func searchDictWordAdd(word string) bool {
+
+	if word = searchDictWord(word); word == "" {
+		return false
+	}
+
+	searchDictRw.Lock()
+	defer searchDictRw.Unlock()
+
+	if searchDict[word] {
+		return false
+	}
+	searchDict[word] = true
+
+	return true
+}
+
+func init() {
+
+	var (
+		half = []rune(searchKanaHalfwidth)
+		full = []rune(searchKanaFullwidth)
+	)
+	for i := 0; i < len(half) && i < len(full); i++ {
+		searchFoldKana[half[i]] = full[i]
+	}
+
+	for _, v := range strings.Fields(searchHansFolds) {
+		if rs := []rune(v); len(rs) == 2 && rs[0] != rs[1] {
+			searchFoldHans[rs[0]] = rs[1]
+		}
+	}
+}
+
+// SearchTextNormalize folds full-width and half-width forms, Traditional
+// Chinese characters and letter case into one canonical form.
+func SearchTextNormalize(txt string) string {
+
+	return strings.Map(func(r rune) rune {
+
+		switch {
+
+		case r == 0x3000:
+			return ' '
+
+		case r >= 0xFF01 && r <= 0xFF5E:
+			r -= 0xFEE0
+
+		case r == 0xFF9E || r == 0xFF9F:
+			return -1
+
+		case r >= 0xFF61 && r <= 0xFF9D:
+			if v, ok := searchFoldKana[r]; ok {
+				r = v
+			}
+
+		default:
+			if v, ok := searchFoldHans[r]; ok {
+				r = v
+			}
+		}
+
+		return unicode.ToLower(r)
+	}, txt)
+}
+
+func searchIsCjk(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+type searchCjkTokenizer struct{}
+
+func (it *searchCjkTokenizer) Tokens(txt string) []string {
+
+	searchDictInit()
+
+	var (
+		tokens = []string{}
+		word   = []rune{}
+		cjk    = []rune{}
+	)
+
+	wordFlush := func() {
+		if len(word) > 0 {
+			tokens = append(tokens, string(word))
+			word = word[:0]
+		}
+	}
+
+	cjkFlush := func() {
+		if len(cjk) > 0 {
+			tokens = append(tokens, searchCjkSegment(cjk)...)
+			cjk = cjk[:0]
+		}
+	}
+
+	for _, r := range SearchTextNormalize(txt) {
+
+		switch {
+
+		case searchIsCjk(r):
+			wordFlush()
+			cjk = append(cjk, r)
+
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			cjkFlush()
+			word = append(word, r)
+
+		default:
+			wordFlush()
+			cjkFlush()
+		}
+	}
+
+	wordFlush()
+	cjkFlush()
+
+	return tokens
+}
+
+// searchCjkSegment splits a run of CJK characters by forward maximum
+// matching against the dictionary, the unmatched parts fall back to bigrams.
+func searchCjkSegment(rs []rune) []string {
+
+	searchDictRw.RLock()
+	defer searchDictRw.RUnlock()
+
+	var (
+		tokens = []string{}
+		miss   = []rune{}
+	)
+
+	missFlush := func() {
+		if len(miss) == 1 {
+			tokens = append(tokens, string(miss))
+		}
+		for i := 0; i+1 < len(miss); i++ {
+			tokens = append(tokens, string(miss[i:i+2]))
+		}
+		miss = miss[:0]
+	}
+
+	for i := 0; i < len(rs); {
+
+		n := searchDictMatch(rs[i:])
+		if n < 2 {
+			miss = append(miss, rs[i])
+			i += 1
+			continue
+		}
+
+		missFlush()
+
+		tokens = append(tokens, string(rs[i:i+n]))
+		tokens = append(tokens, searchDictSubWords(rs[i:i+n])...)
+
+		i += n
+	}
+
+	missFlush()
+
+	return tokens
+}
+
+func searchDictMatch(rs []rune) int {
+
+	n := len(rs)
+	if n > searchDictWordMax {
+		n = searchDictWordMax
+	}
+
+	for ; n >= 2; n-- {
+		if _, ok := searchDict[string(rs[:n])]; ok {
+			return n
+		}
+	}
+
+	return 0
+}
+
+func searchDictSubWords(rs []rune) []string {
+
+	words := []string{}
+
+	for i := 0; i < len(rs); i++ {
+		for n := 2; i+n <= len(rs); n++ {
+			if n == len(rs) {
+				break
+			}
+			if _, ok := searchDict[string(rs[i:i+n])]; ok {
+				words = append(words, string(rs[i:i+n]))
+			}
+		}
+	}
+
+	return words
+}
+
+const (
+	searchKanaHalfwidth = "｡｢｣､･ｦｧｨｩｪｫｬｭｮｯｰｱｲｳｴｵｶｷｸｹｺｻｼｽｾｿﾀﾁﾂﾃﾄﾅﾆﾇﾈﾉﾊﾋﾌﾍﾎﾏﾐﾑﾒﾓﾔﾕﾖﾗﾘﾙﾚﾛﾜﾝ"
+	searchKanaFullwidth = "。「」、・ヲァィゥェォャュョッーアイウエオカキクケコサシスセソタチツテトナニヌネノハヒフヘホマミムメモヤユヨラリルレロワン"
+)
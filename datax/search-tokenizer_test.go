@@ -0,0 +1,67 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSearchTextTokens(t *testing.T) {
+
+	for _, v := range [][2]string{
+		{"數據庫系統的設計", "数据库 数据 系统 的 设计"},
+		{"Ｈｅｌｌｏ，世界！Go语言", "hello 世界 go 语言"},
+		{"ｶﾀｶﾅ", "カタ タカ カナ"},
+		{"我爱北京", "我爱 北京"},
+		{"Kubernetes集群部署", "kubernetes 集群 部署"},
+	} {
+		if s := strings.Join(searchTextTokens(v[0]), " "); s != v[1] {
+			t.Fatalf("Failed on Tokens %s, expect %s, got %s", v[0], v[1], s)
+		}
+	}
+}
+
+func TestSearchDictWord(t *testing.T) {
+
+	for _, v := range [][2]string{
+		{"雲原生", "云原生"},
+		{"北京", "北京"},
+		{"云", ""},
+		{"hello", ""},
+		{"云native", ""},
+		{"一二三四五六七八九", ""},
+	} {
+		if s := searchDictWord(v[0]); s != v[1] {
+			t.Fatalf("Failed on DictWord %s, expect %s, got %s", v[0], v[1], s)
+		}
+	}
+
+	searchDictInit()
+
+	defer func() {
+		searchDictRw.Lock()
+		delete(searchDict, "鸿图霸业")
+		searchDictRw.Unlock()
+	}()
+
+	if !searchDictWordAdd("鸿图霸业") || searchDictWordAdd("鸿图霸业") || searchDictWordAdd("hello") {
+		t.Fatalf("Failed on DictWordAdd")
+	}
+
+	if s := strings.Join(searchTextTokens("鸿图霸业"), " "); s != "鸿图霸业" {
+		t.Fatalf("Failed on Tokens %s, expect %s, got %s", "鸿图霸业", "鸿图霸业", s)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hooto/hpress/api"
+)
+
+const (
+	seoTitleLength       = 70
+	seoDescriptionLength = 160
+)
+
+var (
+	seoImageReg = regexp.MustCompile(`(\{\{hp_storage_service_endpoint\}\}|/hp/s2/)[^\s"'<>()]+\.(?i:png|jpe?g|gif|webp)`)
+)
+
+// NodeSeoMeta returns the head metadata of the node, the values not set
+// by the SEO extension are derived from the title, the summary (or the
+// first text field) and the first S2 image of the node. Canonical and
+// Image may be site relative paths.
+func NodeSeoMeta(mod *api.Spec, table string, node *api.Node, lang string) api.NodeSeo {
+
+	var seo api.NodeSeo
+	if node.ExtSeo != nil {
+		seo = *node.ExtSeo
+	}
+
+	if seo.Title == "" {
+		seo.Title = StringSub(TextHtml2Str(FieldStringPrint(*node, "title", lang)), 0, seoTitleLength)
+	}
+
+	texts := []string{}
+
+	if model := mod.NodeModelGet(table); model != nil {
+		for _, field := range model.Fields {
+			if field.Type == "text" || field.Name == "summary" {
+				texts = append(texts, field.Name)
+			}
+		}
+	}
+
+	if seo.Description == "" {
+		col := ""
+		for _, name := range texts {
+			if name == "summary" {
+				col = name
+				break
+			} else if col == "" {
+				col = name
+			}
+		}
+		if col != "" {
+			seo.Description = strings.TrimSpace(strings.Join(strings.Fields(
+				TextHtml2Str(string(FieldHtmlSubPrint(*node, col, seoDescriptionLength, lang)))), " "))
+		}
+	}
+
+	if seo.Image == "" {
+		for _, name := range texts {
+			if v := seoImageReg.FindString(FieldStringPrint(*node, name, lang)); v != "" {
+				seo.Image = s2_replace(v)
+				break
+			}
+		}
+	}
+
+	if seo.Canonical == "" {
+		seo.Canonical = NodePermalink(mod, table, node)
+	}
+
+	return seo
+}
@@ -0,0 +1,270 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/hooto/hlog4g/hlog"
+	"github.com/hooto/htoml4g/htoml"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+type siteInstance struct {
+	entry     *api.Site
+	languages []*api.LangEntry
+	theme     map[string]string
+}
+
+var (
+	siteMu     sync.RWMutex
+	siteHosts  map[string]*siteInstance
+	siteByName map[string]*siteInstance
+)
+
+func SiteList() api.SiteList {
+
+	var (
+		ls     api.SiteList
+		prefix = api.NsSite("")
+		offset = prefix
+	)
+
+	for {
+
+		rs := store.DataLocal.NewReader(nil).KeyRangeSet(offset, prefix).
+			LimitNumSet(100).Query()
+
+		for _, v := range rs.Items {
+			offset = v.Meta.Key
+			var entry api.Site
+			if err := v.Decode(&entry); err == nil {
+				ls.Items = append(ls.Items, &entry)
+			}
+		}
+
+		if !rs.Next {
+			break
+		}
+	}
+
+	ls.Kind = "SiteList"
+
+	return ls
+}
+
+func SiteEntry(name string) *api.Site {
+
+	var entry api.Site
+	if rs := store.DataLocal.NewReader(api.NsSite(name)).Query(); rs.OK() {
+		if err := rs.Decode(&entry); err == nil {
+			return &entry
+		}
+	}
+
+	return nil
+}
+
+func SiteSet(entry *api.Site) error {
+
+	if err := entry.Valid(); err != nil {
+		return err
+	}
+
+	for _, v := range SiteList().Items {
+		if v.Name == entry.Name {
+			entry.Created = v.Created
+			continue
+		}
+		for _, host := range entry.Hosts {
+			for _, host2 := range v.Hosts {
+				if host == host2 {
+					return fmt.Errorf("Host (%s) is already used by Site (%s)", host, v.Name)
+				}
+			}
+		}
+	}
+
+	tn := time.Now().Unix()
+	if entry.Created == 0 {
+		entry.Created = tn
+	}
+	entry.Updated = tn
+
+	if rs := store.DataLocal.NewWriter(api.NsSite(entry.Name), entry).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Put Error")
+	}
+
+	siteRefresh()
+	PageCachePurge(PageCacheTagPagelets())
+
+	return nil
+}
+
+func SiteDel(name string) error {
+
+	if !api.SiteNameReg.MatchString(name) {
+		return errors.New("Invalid Site Name")
+	}
+
+	if rs := store.DataLocal.NewWriter(api.NsSite(name), nil).ModeDeleteSet(true).Commit(); !rs.OK() {
+		return errors.New("DataLocal/Delete Error")
+	}
+
+	siteRefresh()
+	PageCachePurge(PageCacheTagPagelets())
+
+	return nil
+}
+
+func siteRefresh() {
+
+	var (
+		hosts  = map[string]*siteInstance{}
+		byName = map[string]*siteInstance{}
+	)
+
+	for _, entry := range SiteList().Items {
+
+		inst := &siteInstance{
+			entry: entry,
+		}
+
+		for _, lv := range api.LangsStringFilterArray(entry.Languages) {
+			for _, lv2 := range api.LangArray {
+				if lv == lv2.Id {
+					inst.languages = append(inst.languages, lv2)
+				}
+			}
+		}
+
+		if entry.ThemeConfig != "" {
+			if err := htoml.Decode(&inst.theme, []byte(entry.ThemeConfig)); err != nil {
+				hlog.Printf("warn", "site %s, theme config err %s", entry.Name, err.Error())
+			}
+		}
+
+		for _, host := range entry.Hosts {
+			hosts[host] = inst
+		}
+		byName[entry.Name] = inst
+	}
+
+	siteMu.Lock()
+	siteHosts, siteByName = hosts, byName
+	siteMu.Unlock()
+}
+
+func siteInstanceLookup(host string) *siteInstance {
+
+	siteMu.RLock()
+	loaded := siteHosts != nil
+	siteMu.RUnlock()
+
+	if !loaded {
+		siteRefresh()
+	}
+
+	host = api.SiteHostFilter(host)
+
+	siteMu.RLock()
+	defer siteMu.RUnlock()
+
+	if inst, ok := siteHosts[host]; ok {
+		return inst
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		if inst, ok := siteHosts[h]; ok {
+			return inst
+		}
+	}
+
+	return nil
+}
+
+// SiteLookup returns the site of the request host, or nil if the host is
+// served by the global settings
+func SiteLookup(host string) *api.Site {
+	if inst := siteInstanceLookup(host); inst != nil {
+		return inst.entry
+	}
+	return nil
+}
+
+// SiteLanguages returns the languages of the site, or the global languages
+func SiteLanguages(site *api.Site) []*api.LangEntry {
+	if site != nil {
+		siteMu.RLock()
+		defer siteMu.RUnlock()
+		if inst, ok := siteByName[site.Name]; ok && len(inst.languages) > 0 {
+			return inst.languages
+		}
+	}
+	return config.Languages
+}
+
+// SiteTheme returns the theme config of the site
+func SiteTheme(site *api.Site) map[string]string {
+	if site != nil {
+		siteMu.RLock()
+		defer siteMu.RUnlock()
+		if inst, ok := siteByName[site.Name]; ok && inst.theme != nil {
+			return inst.theme
+		}
+	}
+	return map[string]string{}
+}
+
+// SiteThemeConfig is the site aware template function of ThemeConfig, the
+// theme of the site overrides the theme of the module of the page.
+//
+//	{{SiteThemeConfig $ "code_theme" "monokai"}}
+func SiteThemeConfig(data map[string]interface{}, key string, args ...string) string {
+
+	site, _ := data["__site__"].(*api.Site)
+	if v, ok := SiteTheme(site)[key]; ok && v != "" {
+		return v
+	}
+
+	srvname, _ := data["srvname"].(string)
+
+	return config.ThemeConfigFetchString(srvname, key, args...)
+}
+
+// SiteConfigFetchString returns the site override of the SysConfig key,
+// or the global value
+func SiteConfigFetchString(site *api.Site, key string) string {
+	if site != nil {
+		if v, ok := site.ConfigFetch(key); ok {
+			return v
+		}
+	}
+	return config.SysConfigList.FetchString(key)
+}
+
+// SiteConfig is the template function of SiteConfigFetchString, the site
+// is taken from the page data, e.g. {{SiteConfig $ "frontend_header_site_name"}}
+func SiteConfig(data map[string]interface{}, key string) string {
+	site, _ := data["__site__"].(*api.Site)
+	return SiteConfigFetchString(site, key)
+}
@@ -0,0 +1,326 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lessos/lessgo/utils"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/store"
+)
+
+const (
+	SitemapUrlsMax  = 50000
+	SitemapBytesMax = 50 * 1024 * 1024
+)
+
+type SitemapUrl struct {
+	Path    string
+	Updated uint32
+}
+
+// SitemapFile is one file of a module sitemap, the urls are split at the
+// protocol limits of 50,000 urls and 50MB.
+type SitemapFile struct {
+	Urls    []*SitemapUrl
+	Updated uint32
+}
+
+type sitemapCache struct {
+	files   []*SitemapFile
+	created int64
+}
+
+var (
+	sitemapMu       sync.RWMutex
+	sitemapCaches   = map[string]*sitemapCache{}
+	sitemapCacheTTL = int64(3600)
+)
+
+// SitemapModules returns the enabled modules with frontend pages
+func SitemapModules() []*api.Spec {
+
+	ls := []*api.Spec{}
+	for _, mod := range config.Modules {
+		if mod.Status == 1 && mod.Meta.Name != "core/comment" && len(mod.Router.Routes) > 0 {
+			ls = append(ls, mod)
+		}
+	}
+
+	sort.Slice(ls, func(i, j int) bool {
+		return ls[i].SrvName < ls[j].SrvName
+	})
+
+	return ls
+}
+
+// SitemapFiles returns the cached sitemap files of the module, they are
+// built again after SitemapPurge, which is called by the write hooks of
+// nodes and terms, or after sitemapCacheTTL for the changes of other writers.
+func SitemapFiles(mod *api.Spec) []*SitemapFile {
+
+	tn := time.Now().Unix()
+
+	sitemapMu.RLock()
+	cache, ok := sitemapCaches[mod.Meta.Name]
+	sitemapMu.RUnlock()
+
+	if ok && cache.created+sitemapCacheTTL > tn {
+		return cache.files
+	}
+
+	files := sitemapFilesSplit(sitemapModuleUrls(mod), len(config.Languages))
+
+	sitemapMu.Lock()
+	sitemapCaches[mod.Meta.Name] = &sitemapCache{
+		files:   files,
+		created: tn,
+	}
+	sitemapMu.Unlock()
+
+	return files
+}
+
+func SitemapPurge(modname string) {
+	sitemapMu.Lock()
+	delete(sitemapCaches, modname)
+	sitemapMu.Unlock()
+}
+
+func sitemapFilesSplit(urls []*SitemapUrl, langs int) []*SitemapFile {
+
+	var (
+		files = []*SitemapFile{}
+		file  = &SitemapFile{}
+		size  = 0
+	)
+
+	for _, u := range urls {
+
+		// the estimated bytes of the <url> entry and its alternates
+		n := 100 + len(u.Path)
+		if langs > 1 {
+			n += (langs + 1) * (80 + len(u.Path))
+		}
+
+		if len(file.Urls) >= SitemapUrlsMax || size+n > SitemapBytesMax-1024 {
+			files = append(files, file)
+			file, size = &SitemapFile{}, 0
+		}
+
+		file.Urls = append(file.Urls, u)
+		size += n
+		if u.Updated > file.Updated {
+			file.Updated = u.Updated
+		}
+	}
+
+	if len(file.Urls) > 0 {
+		files = append(files, file)
+	}
+
+	return files
+}
+
+func sitemapModuleUrls(mod *api.Spec) []*SitemapUrl {
+
+	var (
+		urls    = []*SitemapUrl{}
+		updated = uint32(0)
+		nodes   = map[string]map[string]*api.Node{}
+	)
+
+	for _, model := range mod.NodeModels {
+		nodes[model.Meta.Name] = sitemapNodes(mod, model)
+	}
+
+	for _, model := range mod.NodeModels {
+
+		ids := []string{}
+		for id := range nodes[model.Meta.Name] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		for _, id := range ids {
+
+			var (
+				node = nodes[model.Meta.Name][id]
+				path string
+			)
+
+			if model.Extensions.NodeRefer != "" {
+				if node.ExtNodeRefer == "" {
+					continue
+				}
+				refer, ok := nodes[model.Extensions.NodeRefer][node.ExtNodeRefer]
+				if !ok {
+					continue
+				}
+				path = NodeReferPermalink(mod, model.Meta.Name, node, model.Extensions.NodeRefer, refer)
+			} else {
+				path = NodePermalink(mod, model.Meta.Name, node)
+			}
+
+			if path == "" {
+				continue
+			}
+
+			urls = append(urls, &SitemapUrl{
+				Path:    path,
+				Updated: node.Updated,
+			})
+
+			if node.Updated > updated {
+				updated = node.Updated
+			}
+		}
+	}
+
+	pages := []*SitemapUrl{}
+
+	for _, route := range mod.Router.Routes {
+
+		path := routeStaticPath(mod, &route)
+		if path == "" {
+			continue
+		}
+
+		pages = append(pages, &SitemapUrl{
+			Path:    path,
+			Updated: updated,
+		})
+
+		pages = append(pages, sitemapTermUrls(mod, route.DataAction, path)...)
+	}
+
+	return append(pages, urls...)
+}
+
+// routeStaticPath returns the path of the route without required params,
+// or an empty string
+func routeStaticPath(mod *api.Spec, route *api.Route) string {
+	if path, ok := route.Build(nil); ok {
+		return nodePermalinkJoin(mod, path)
+	}
+	return ""
+}
+
+func sitemapNodes(mod *api.Spec, model *api.NodeModel) map[string]*api.Node {
+
+	var (
+		ls    = map[string]*api.Node{}
+		table = fmt.Sprintf("hpn_%s_%s", utils.StringEncode16(mod.Meta.Name, 12), model.Meta.Name)
+		cols  = "id,updated"
+	)
+
+	if model.Extensions.Permalink != "" {
+		cols += ",ext_permalink_name"
+	}
+	if model.Extensions.NodeRefer != "" {
+		cols += ",ext_node_refer"
+	}
+
+	for offset := int64(0); ; offset += 1000 {
+
+		q := store.Data.NewQueryer().Select(cols).From(table).
+			Order("id asc").Limit(1000).Offset(offset)
+		q.Where().And("status", 1)
+
+		rs, err := store.Data.Query(q)
+		if err != nil {
+			break
+		}
+
+		for _, v := range rs {
+
+			node := &api.Node{
+				ID:      v.Field("id").String(),
+				Updated: v.Field("updated").Uint32(),
+			}
+
+			if model.Extensions.Permalink != "" {
+				node.ExtPermalinkName = v.Field("ext_permalink_name").String()
+			}
+			if node.ExtPermalinkName == "" {
+				node.SelfLink = node.ID + ".html"
+			}
+
+			if model.Extensions.NodeRefer != "" {
+				node.ExtNodeRefer = v.Field("ext_node_refer").String()
+			}
+
+			ls[node.ID] = node
+		}
+
+		if len(rs) < 1000 {
+			break
+		}
+	}
+
+	return ls
+}
+
+func sitemapTermUrls(mod *api.Spec, actionName, path string) []*SitemapUrl {
+
+	urls := []*SitemapUrl{}
+
+	for _, action := range mod.Actions {
+
+		if action.Name != actionName {
+			continue
+		}
+
+		for _, ad := range action.Datax {
+
+			if ad.Type != "node.list" {
+				continue
+			}
+
+			model, err := config.SpecNodeModel(mod.Meta.Name, ad.Query.Table)
+			if err != nil {
+				continue
+			}
+
+			for _, term := range model.Terms {
+
+				if term.Type != api.TermTaxonomy {
+					continue
+				}
+
+				qry := NewQuery(mod.Meta.Name, term.Meta.Name)
+				qry.Limit(1000)
+				ls := qry.TermList()
+
+				for _, v := range ls.Items {
+					urls = append(urls, &SitemapUrl{
+						Path:    fmt.Sprintf("%s?term_%s=%d", path, term.Meta.Name, v.ID),
+						Updated: v.Updated,
+					})
+				}
+			}
+		}
+
+		break
+	}
+
+	return urls
+}
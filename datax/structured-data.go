@@ -0,0 +1,236 @@
+// Copyright 2019 Eryx <evorui аt gmail dοt com>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datax
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+)
+
+type jsonLdObject map[string]interface{}
+
+// JsonLD renders the schema.org structured data of the page as a
+// application/ld+json script. With no arguments it returns the WebSite
+// entry, otherwise each argument names a node.entry of the page data,
+// which is mapped by the StructuredData of its model and followed by
+// its BreadcrumbList.
+//
+//	{{JsonLD .}}
+//	{{JsonLD . "entry"}}
+func JsonLD(data map[string]interface{}, args ...string) template.HTML {
+
+	var (
+		srvname, _ = data["srvname"].(string)
+		site, _    = data["__html_site_url__"].(string)
+		lang, _    = data["LANG"].(string)
+		siteName   = SiteConfig(data, "frontend_header_site_name")
+		graph      = []jsonLdObject{}
+	)
+
+	mod, ok := config.Modules[srvname]
+	if !ok {
+		return ""
+	}
+
+	if len(args) == 0 {
+		graph = append(graph, jsonLdWebSite(mod, site, siteName))
+	}
+
+	for _, name := range args {
+
+		node, ok := data[name].(api.Node)
+		if !ok || node.ID == "" || node.Model == nil || node.Model.StructuredData == nil {
+			continue
+		}
+
+		link := NodePermalink(mod, node.Model.Meta.Name, &node)
+		if link == "" {
+			link, _ = data["http_request_path"].(string)
+		}
+		link = site + link
+
+		graph = append(graph,
+			jsonLdNode(mod, &node, link, site, siteName, lang),
+			jsonLdBreadcrumbList(data, mod, &node, link, site, lang))
+	}
+
+	if len(graph) == 0 {
+		return ""
+	}
+
+	doc := jsonLdObject{
+		"@context": "https://schema.org",
+		"@graph":   graph,
+	}
+
+	bs, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+
+	return template.HTML(`<script type="application/ld+json">` + string(bs) + `</script>`)
+}
+
+func jsonLdWebSite(mod *api.Spec, site, name string) jsonLdObject {
+
+	if name == "" {
+		name = mod.Title
+	}
+
+	return jsonLdObject{
+		"@type": "WebSite",
+		"name":  name,
+		"url":   site + "/",
+		"potentialAction": jsonLdObject{
+			"@type":       "SearchAction",
+			"target":      site + "/" + mod.SrvName + "/?qry_text={search_term_string}",
+			"query-input": "required name=search_term_string",
+		},
+	}
+}
+
+func jsonLdNode(mod *api.Spec, node *api.Node, link, site, siteName, lang string) jsonLdObject {
+
+	var (
+		sd  = node.Model.StructuredData
+		seo = NodeSeoMeta(mod, node.Model.Meta.Name, node, lang)
+		obj = jsonLdObject{
+			"@type":            sd.Type,
+			"mainEntityOfPage": link,
+			"url":              link,
+			"datePublished":    time.Unix(int64(node.Created), 0).UTC().Format(time.RFC3339),
+			"dateModified":     time.Unix(int64(node.Updated), 0).UTC().Format(time.RFC3339),
+		}
+	)
+
+	if sd.Headline != "" {
+		obj["headline"] = StringSub(TextHtml2Str(FieldStringPrint(*node, sd.Headline, lang)), 0, 110)
+	} else {
+		obj["headline"] = seo.Title
+	}
+
+	if sd.Description != "" {
+		obj["description"] = strings.TrimSpace(TextHtml2Str(
+			string(FieldHtmlSubPrint(*node, sd.Description, seoDescriptionLength, lang))))
+	} else if seo.Description != "" {
+		obj["description"] = seo.Description
+	}
+
+	if sd.Body != "" {
+		obj["articleBody"] = TextHtml2Str(string(FieldHtmlPrint(*node, sd.Body, lang)))
+	}
+
+	if seo.Image != "" {
+		if strings.HasPrefix(seo.Image, "/") {
+			obj["image"] = jsonLdHost(site) + seo.Image
+		} else {
+			obj["image"] = seo.Image
+		}
+	}
+
+	if node.UserID != "" {
+		obj["author"] = jsonLdObject{
+			"@type": "Person",
+			"name":  node.UserID,
+		}
+	}
+
+	if siteName != "" {
+		obj["publisher"] = jsonLdObject{
+			"@type": "Organization",
+			"name":  siteName,
+		}
+	}
+
+	if lang != "" {
+		obj["inLanguage"] = lang
+	}
+
+	for _, term := range node.Terms {
+
+		titles := []string{}
+		for _, v := range term.Items {
+			if v.Title != "" {
+				titles = append(titles, v.Title)
+			}
+		}
+
+		if len(titles) == 0 {
+			continue
+		}
+
+		switch term.Name {
+		case sd.Keywords:
+			obj["keywords"] = strings.Join(titles, ", ")
+		case sd.Section:
+			obj["articleSection"] = titles[0]
+		}
+	}
+
+	return obj
+}
+
+func jsonLdBreadcrumbList(data map[string]interface{}, mod *api.Spec, node *api.Node, link, site, lang string) jsonLdObject {
+
+	items := []jsonLdObject{}
+
+	if crumbs := Breadcrumbs(data); len(crumbs) > 1 {
+		for i, v := range crumbs {
+			item := jsonLdObject{
+				"@type":    "ListItem",
+				"position": len(items) + 1,
+				"name":     v.Label,
+			}
+			if i+1 == len(crumbs) {
+				item["item"] = link
+			} else if v.path != "" {
+				item["item"] = site + v.path
+			}
+			items = append(items, item)
+		}
+	} else {
+		items = append(items, jsonLdObject{
+			"@type":    "ListItem",
+			"position": 1,
+			"name":     mod.Title,
+			"item":     site + "/" + mod.SrvName + "/",
+		}, jsonLdObject{
+			"@type":    "ListItem",
+			"position": 2,
+			"name":     TextHtml2Str(FieldStringPrint(*node, "title", lang)),
+			"item":     link,
+		})
+	}
+
+	return jsonLdObject{
+		"@type":           "BreadcrumbList",
+		"itemListElement": items,
+	}
+}
+
+// jsonLdHost returns the scheme and host of the site url
+func jsonLdHost(site string) string {
+	if i := strings.Index(site, "://"); i > 0 {
+		if j := strings.Index(site[i+3:], "/"); j > 0 {
+			return site[:i+3+j]
+		}
+	}
+	return site
+}
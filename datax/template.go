@@ -31,20 +31,28 @@ import (
 
 func FilterUri(data map[string]interface{}, args ...interface{}) template.URL {
 
-	uris := []string{}
+	var (
+		uris = []string{}
+		sets = map[string]bool{}
+	)
+
+	if len(args) > 1 {
+		for i := 0; i+1 < len(args); i += 2 {
+			uris = append(uris, fmt.Sprintf("%v=%v", args[i], args[i+1]))
+			sets[fmt.Sprintf("%v", args[i])] = true
+		}
+	}
 
 	for key, val := range data {
 
-		if key == "qry_text" {
-			uris = append(uris, fmt.Sprintf("qry_text=%v", val))
-		} else if len(key) > 5 && key[:5] == "term_" {
-			uris = append(uris, fmt.Sprintf("%s=%v", key, val))
+		if sets[key] {
+			continue
 		}
-	}
 
-	if len(args) > 1 {
-		for i := 0; i < len(args); i += 2 {
-			uris = append(uris, fmt.Sprintf("%v=%v", args[i], args[i+1]))
+		if key == "qry_text" || key == "date_from" || key == "date_to" {
+			uris = append(uris, fmt.Sprintf("%s=%v", key, val))
+		} else if len(key) > 5 && key[:5] == "term_" {
+			uris = append(uris, fmt.Sprintf("%s=%v", key, val))
 		}
 	}
 
@@ -56,9 +64,17 @@ func FilterUri(data map[string]interface{}, args ...interface{}) template.URL {
 }
 
 var (
-	varNameRE = regexp.MustCompile("[a-zA-Z0-9_]{1,30}")
+	varNameRE = regexp.MustCompile("^[a-zA-Z0-9_]{1,30}$")
 )
 
+// Pagelet renders the template of the module with the optional data action
+// and "name=value" variables. The rendered fragment is cached when the
+// invocation declares "cache_ttl=<seconds>", the cache key varies by the
+// arguments, the site, the language and the data keys of "cache_vary",
+// and it is purged by the writes of the nodes and terms of the action.
+//
+//	{{pagelet . "core/general" "v2/footer.tpl" "cache_ttl=600"}}
+//	{{pagelet . "core/blog" "sidebar.tpl" "list" "cache_ttl=300" "cache_vary=term_categories"}}
 func Pagelet(data map[string]interface{}, args ...string) template.HTML {
 
 	defer func() {
@@ -67,6 +83,8 @@ func Pagelet(data map[string]interface{}, args ...string) template.HTML {
 		}
 	}()
 
+	args, cacheTTL, cacheVary := pageletCacheArgs(args)
+
 	//
 	if len(args) < 2 || len(args) > 10 {
 		return ""
@@ -81,6 +99,39 @@ func Pagelet(data map[string]interface{}, args ...string) template.HTML {
 		}
 	}
 
+	//
+	user, _ := data["s_user"]
+	if user == "" || user == nil {
+		user = "guest"
+	}
+
+	// the enclosing page is tagged with the data of its pagelets
+	tags := pageletCacheTags(args)
+	pageCacheDataTagsAdd(data, tags...)
+
+	// signed-in pages are never cached, same as the page output cache
+	if cacheTTL < 1 || user != "guest" {
+		return pageletRender(data, user, args)
+	}
+
+	cacheKey := pageletCacheKey(data, args, cacheVary)
+	if entry := PageCacheEntry(cacheKey); entry != nil {
+		return template.HTML(entry.Body)
+	}
+
+	html := pageletRender(data, user, args)
+	if html != "" {
+		PageCachePut(cacheKey, &api.PageCacheEntry{
+			Body: string(html),
+			Tags: tags,
+		}, cacheTTL)
+	}
+
+	return html
+}
+
+func pageletRender(data map[string]interface{}, user interface{}, args []string) template.HTML {
+
 	//
 	modname, templatePath := args[0], args[1]
 	if len(args) == 2 {
@@ -89,12 +140,6 @@ func Pagelet(data map[string]interface{}, args ...string) template.HTML {
 	}
 	// fmt.Println("Pagelet", modname, args)
 
-	//
-	user, _ := data["s_user"]
-	if user == "" {
-		user = "guest"
-	}
-
 	//
 	for _, spec := range config.Modules {
 
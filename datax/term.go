@@ -338,6 +338,28 @@ func NodeTermQuery(modname string, model *api.NodeModel, terms []api.NodeTerm) [
 	return terms
 }
 
+func TermTagID(modname, modelid, title string) uint32 {
+
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return 0
+	}
+
+	h := md5.New()
+	io.WriteString(h, strings.ToLower(title))
+
+	table := fmt.Sprintf("hpt_%s_%s", utils.StringEncode16(modname, 12), modelid)
+
+	q := store.Data.NewQueryer().From(table).Limit(1)
+	q.Where().And("uid", fmt.Sprintf("%x", h.Sum(nil))[:16])
+
+	if rs, err := store.Data.Query(q); err == nil && len(rs) > 0 {
+		return rs[0].Field("id").Uint32()
+	}
+
+	return 0
+}
+
 func TermSync(modname, modelid, terms string) (TermList, error) {
 
 	ls := TermList{}
@@ -420,6 +442,7 @@ func TermSync(modname, modelid, terms string) (TermList, error) {
 
 			if incrid, err := rs.LastInsertId(); err == nil && incrid > 0 {
 				ls.Items[tk].ID = uint32(incrid)
+				TermChanged(modname, modelid, ls.Items[tk].ID)
 			}
 		}
 	}
@@ -30,7 +30,15 @@ func init() {
 	httpsrv.GlobalService.Config.TemplateFuncRegister("FieldSubString", FieldSubString)
 	httpsrv.GlobalService.Config.TemplateFuncRegister("FieldHtml", FieldHtml)
 	httpsrv.GlobalService.Config.TemplateFuncRegister("FieldSubHtml", FieldSubHtml)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("SearchExcerptPrint", SearchExcerptPrint)
 	httpsrv.GlobalService.Config.TemplateFuncRegister("pagelet", Pagelet)
 	httpsrv.GlobalService.Config.TemplateFuncRegister("FilterUri", FilterUri)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("JsonLD", JsonLD)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("SiteConfig", SiteConfig)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("SiteThemeConfig", SiteThemeConfig)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("Menu", Menu)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("MenuRender", MenuRender)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("Breadcrumbs", Breadcrumbs)
+	httpsrv.GlobalService.Config.TemplateFuncRegister("BreadcrumbsRender", BreadcrumbsRender)
 	httpsrv.GlobalService.Config.TemplateFuncRegister("T", hlang.StdLangFeed.Translate)
 }
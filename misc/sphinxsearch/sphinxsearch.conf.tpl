@@ -24,9 +24,8 @@ index {{$v.Name}}_full
 
     stopwords       = {{$.config.Prefix}}/misc/sphinxsearch/stopword.conf
     min_word_len    = 1
-    charset_table   = 0..9, A..Z->a..z, _, a..z, U+410..U+42F->U+430..U+44F, U+430..U+44F
-    ngram_len       = 1
-    ngram_chars     = U+3000..U+2FA1F
+    charset_table   = 0..9, A..Z->a..z, _, a..z, U+410..U+42F->U+430..U+44F, U+430..U+44F, \
+                      U+3040..U+30FF, U+3400..U+4DBF, U+4E00..U+9FFF, U+AC00..U+D7AF, U+F900..U+FAFF
 
     phrase_boundary_step    = 100
     html_strip              = 0
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hooto/hlog4g/hlog"
 	"github.com/lessos/lessgo/crypto/idhash"
 	"github.com/lessos/lessgo/encoding/json"
 	"github.com/lessos/lessgo/types"
@@ -33,6 +34,7 @@ import (
 
 	"github.com/hooto/hpress/api"
 	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
 	"github.com/hooto/hpress/store"
 )
 
@@ -40,7 +42,7 @@ var (
 	modNamePattern        = regexp.MustCompile("^[0-9a-z/]{3,30}$")
 	modelNamePattern      = regexp.MustCompile("^[a-z]{1,1}[0-9a-z_]{1,20}$")
 	nodeFeildNamePattern  = regexp.MustCompile("^[a-z]{1,1}[0-9a-z_]{1,20}$")
-	routePathPattern      = regexp.MustCompile("^[0-9a-zA-Z_/\\-:]{1,50}$")
+	routePathPattern      = regexp.MustCompile("^[0-9a-zA-Z_/\\-:*?]{1,50}$")
 	routeParamNamePattern = regexp.MustCompile("^[a-z]{1,1}[0-9a-zA-Z_]{0,29}$")
 )
 
@@ -137,6 +139,13 @@ func SpecInfoSet(entry api.Spec) error {
 		entry.Status = 0
 	}
 
+	prevSrvName := prev.SrvName
+	if mod := config.SpecGet(entry.Meta.Name); mod != nil {
+		prevSrvName = mod.SrvName
+	} else if prevSrvName == "" || strings.Contains(prevSrvName, "/") {
+		prevSrvName, _ = api.SrvNameFilter(prev.Meta.Name)
+	}
+
 	if prev.Title != entry.Title ||
 		prev.SrvName != entry.SrvName ||
 		prev.Status != entry.Status ||
@@ -152,6 +161,17 @@ func SpecInfoSet(entry api.Spec) error {
 		if err := spec_config_file_sync(prev); err != nil {
 			return err
 		}
+
+		if err == nil && prevSrvName != "" && prevSrvName != prev.SrvName {
+			if err := datax.RedirectSet(&api.Redirect{
+				From:   "/" + prevSrvName,
+				To:     "/" + prev.SrvName,
+				Prefix: true,
+				Auto:   true,
+			}); err != nil {
+				hlog.Printf("warn", "spec %s redirect set, err %s", prev.Meta.Name, err.Error())
+			}
+		}
 	}
 
 	return err
@@ -294,6 +314,10 @@ func SpecNodeSet(modname string, entry *api.NodeModel) error {
 		}
 	}
 
+	if entry.StructuredData != nil && entry.StructuredData.Type == "" {
+		entry.StructuredData = nil
+	}
+
 	prev, err := SpecFetch(modname)
 	if err != nil {
 		return err
@@ -349,6 +373,16 @@ func SpecNodeSet(modname string, entry *api.NodeModel) error {
 				sync = true
 			}
 
+			if nodeModel.Extensions.Seo != entry.Extensions.Seo {
+				prev.NodeModels[i].Extensions.Seo = entry.Extensions.Seo
+				sync = true
+			}
+
+			if !nodeModel.StructuredData.Equal(entry.StructuredData) {
+				prev.NodeModels[i].StructuredData = entry.StructuredData
+				sync = true
+			}
+
 			if len(nodeModel.Fields) != len(entry.Fields) && len(entry.Fields) > 0 {
 
 				prev.NodeModels[i].Fields = entry.Fields
@@ -441,6 +475,14 @@ func SpecNodeSet(modname string, entry *api.NodeModel) error {
 		}
 	}
 
+	for _, v := range prev.NodeModels {
+		if v.StructuredData != nil {
+			if err := v.StructuredData.Valid(v); err != nil {
+				return err
+			}
+		}
+	}
+
 	if sync {
 
 		prev.Meta.Version = api.NewSpecVersion(prev.Meta.Version).Add(0, 0, 1).String()
@@ -683,6 +725,27 @@ func SpecRouteSet(modname string, entry api.Route) error {
 		}
 	}
 
+	if entry.Cache != nil {
+		if err := entry.Cache.Valid(); err != nil {
+			return err
+		}
+		if entry.Cache.Scope == "" && entry.Cache.MaxAge == 0 {
+			entry.Cache = nil
+		}
+	}
+
+	if entry.Feed != nil {
+		if entry.Feed.Format == "" {
+			entry.Feed = nil
+		} else if err := entry.Feed.Valid(); err != nil {
+			return err
+		}
+	}
+
+	if entry.Robots != nil && entry.Robots.Directive() == "" {
+		entry.Robots = nil
+	}
+
 	prev, err := SpecFetch(modname)
 	if err != nil {
 		return err
@@ -698,6 +761,10 @@ func SpecRouteSet(modname string, entry api.Route) error {
 			if entry.DataAction == prevRoute.DataAction &&
 				entry.Template == prevRoute.Template &&
 				entry.Default == prevRoute.Default &&
+				entry.Priority == prevRoute.Priority &&
+				entry.Cache.Equal(prevRoute.Cache) &&
+				entry.Feed.Equal(prevRoute.Feed) &&
+				entry.Robots.Equal(prevRoute.Robots) &&
 				_routeParamsEqual(entry.Params, prevRoute.Params) {
 
 				sync = false
@@ -737,6 +804,10 @@ func SpecRouteSet(modname string, entry api.Route) error {
 		return true
 	})
 
+	if err := api.RoutesValidate(prev.Router.Routes); err != nil {
+		return err
+	}
+
 	if sync {
 
 		prev.Meta.Version = api.NewSpecVersion(prev.Meta.Version).Add(0, 0, 1).String()
@@ -794,7 +865,14 @@ func spec_config_file_sync(entry api.Spec) error {
 	//
 	file := fmt.Sprintf("%s/modules/%s/spec.json", config.Prefix, entry.Meta.Name)
 
-	return json.EncodeToFile(entry, file, "  ")
+	if err := json.EncodeToFile(entry, file, "  "); err != nil {
+		return err
+	}
+
+	datax.SitemapPurge(entry.Meta.Name)
+	datax.MenuPurge()
+
+	return nil
 }
 
 func SpecSchemaSync(spec api.Spec) error {
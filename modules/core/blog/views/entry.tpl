@@ -2,15 +2,14 @@
 <html lang="en">
 {{pagelet . "core/general" "v2/html-header.tpl"}}
 <body id="hp-body">
+{{JsonLD . "entry"}}
 {{pagelet . "core/general" "v2/nav-header.tpl" "topnav"}}
 
 <div class="container" style="margin-top:10px">
 
   <div class="columns">
     <div class="column">
-      <div class="hp-ctn-title">
-        Content Entry
-      </div>
+      {{BreadcrumbsRender $}}
     </div>
   </div>
 </div>
@@ -71,7 +70,7 @@
 
 </div>
 
-{{pagelet . "core/general" "v2/footer.tpl"}}
+{{pagelet . "core/general" "v2/footer.tpl" "cache_ttl=600"}}
 
 <script type="text/javascript">
 {{if .entry.ExtCommentEnable}}
@@ -87,7 +86,7 @@ window.onload_hooks.push(function() {
 {{end}}
 
 window.onload_hooks.push(function() {
-    hp.CodeRender({"theme": "monokai"});
+    hp.CodeRender({"theme": {{SiteThemeConfig $ "code_theme" "monokai"}}});
 });
 
 </script>
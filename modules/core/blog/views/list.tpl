@@ -2,6 +2,7 @@
 <html lang="en">
 {{pagelet . "core/general" "v2/html-header.tpl"}}
 <body id="hp-body">
+{{JsonLD .}}
 
 {{pagelet . "core/general" "v2/nav-header.tpl" "topnav"}}
 
@@ -10,7 +11,8 @@
   <div class="columns">
     <div class="column is-9">
       <div class="hp-ctn-title">
-        Content Explore
+        {{BreadcrumbsRender $}}
+        <a href="{{$.baseuri}}/list?{{FilterUri $ "feed" "rss"}}" title="RSS Feed" style="float:right;font-size:0.8em">RSS</a>
       </div>
     </div>
     <div class="column is-3">
@@ -21,6 +23,8 @@
               placeholder=""
               name="qry_text"
               value="{{.qry_text}}">
+            {{if .date_from}}<input type="hidden" name="date_from" value="{{.date_from}}">{{end}}
+            {{if .date_to}}<input type="hidden" name="date_to" value="{{.date_to}}">{{end}}
           </div>
           <div class="control">
             <input class="button is-dark" type="submit" value="Search">
@@ -36,11 +40,17 @@
 
     <div class="column is-9">
 
+    {{if .list.SearchSuggest}}
+    <div class="hp-search-suggest">
+      Did you mean <a href="{{$.baseuri}}/list?qry_text={{.list.SearchSuggest}}">{{.list.SearchSuggest}}</a> ?
+    </div>
+    {{end}}
+
     <ul class="hp-node-list">
       {{range $v := .list.Items}}
       <li class="hp-node-list-item">
         <h4 class="hp-node-list-heading">
-          <a href="{{$.baseuri}}/view/{{$v.ID}}.html">{{FieldStringPrint $v "title" $.LANG}}</a>
+          <a href="{{$.baseuri}}/view/{{$v.ID}}.html{{if $.list.SearchLogID}}?search_log={{$.list.SearchLogID}}{{end}}">{{if $v.SearchExcerpt}}{{SearchExcerptPrint $v "title"}}{{else}}{{FieldStringPrint $v "title" $.LANG}}{{end}}</a>
         </h4>
         <div class="hp-node-list-info">
 
@@ -76,7 +86,11 @@
 
         </div>
 
+        {{if $v.SearchExcerpt}}
+        <div class="hp-node-list-text">{{SearchExcerptPrint $v "content"}}</div>
+        {{else}}
         <div class="hp-node-list-text">{{FieldHtmlSubPrint $v "content" 200 $.LANG}}</div>
+        {{end}}
       </li>
       {{end}}
     </ul>
@@ -104,12 +118,32 @@
 
     <div class="column is-3">
         {{pagelet . .modname "term/categories.tpl"}}
+
+        {{range $facet := .list.Facets}}
+        {{if $facet.Items}}
+        <div class="hp-search-facet">
+          <div class="hp-search-facet-title">{{if eq $facet.Name "tags"}}Tags{{else}}Categories{{end}}</div>
+          <ul>
+            {{range $item := $facet.Items}}
+            <li>
+              {{if eq $facet.Name "tags"}}
+              <a href="{{$.baseuri}}/list?{{FilterUri $ "term_tags" $item.Title "page" 1}}">{{$item.Title}}</a>
+              {{else}}
+              <a href="{{$.baseuri}}/list?{{FilterUri $ (printf "term_%s" $facet.Name) $item.ID "page" 1}}">{{$item.Title}}</a>
+              {{end}}
+              <span class="hp-search-facet-count">{{$item.Count}}</span>
+            </li>
+            {{end}}
+          </ul>
+        </div>
+        {{end}}
+        {{end}}
     </div>
 
   </div>
 </div>
 
-{{pagelet . "core/general" "v2/footer.tpl"}}
+{{pagelet . "core/general" "v2/footer.tpl" "cache_ttl=600"}}
 
 {{pagelet . "core/general" "html-footer.tpl"}}
 </body>
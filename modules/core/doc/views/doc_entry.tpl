@@ -28,7 +28,7 @@
 </div>
 </div>
     
-{{pagelet . "core/general" "footer.tpl"}}
+{{pagelet . "core/general" "footer.tpl" "cache_ttl=600"}}
 
 
 <script type="text/javascript">
@@ -70,7 +70,7 @@
 </div>
 
 
-{{pagelet . "core/general" "footer.tpl"}}
+{{pagelet . "core/general" "footer.tpl" "cache_ttl=600"}}
 
 {{pagelet . "core/general" "html-footer.tpl"}}
 </body>
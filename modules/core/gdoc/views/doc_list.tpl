@@ -83,7 +83,7 @@
 </div>
 
 
-{{pagelet . "core/general" "v3/footer.tpl"}}
+{{pagelet . "core/general" "v3/footer.tpl" "cache_ttl=600"}}
 
 {{pagelet . "core/general" "html-footer.tpl"}}
 </body>
@@ -5,22 +5,24 @@
 <link rel="stylesheet" href="{{HttpSrvBasePath "hp/~/fa/v5/css/fas.css"}}?v={{.sys_version_sign}}" type="text/css">
 <script src="{{HttpSrvBasePath "hp/-/static/gdoc/js/gdoc.js"}}?v={{.sys_version_sign}}"></script>
 <body id="hp-body">
+{{JsonLD . "doc_entry"}}
 {{pagelet . "core/general" "v3/nav-header.tpl" "topnav" "topbar_class=navbar-light"}}
 
 <div class="hp-container-full hp-gdoc-index-frame-dark-light" style="padding-top:10px;">
 <nav  class="container" >
   <ol class="breadcrumb " style="margin:0">
-        <li class="breadcrumb-item">
-          <span class="icon"><i class="fas fa-file-alt"></i></span>
-          <a href="{{.baseuri}}/" style="margin-left: 10px">
-            <span>{{T .LANG "Documents"}}</span>
-          </a>
-        </li>
-        <li class="breadcrumb-item active">
-          <a href="{{.baseuri}}/view/{{.doc_entry.ExtPermalinkName}}/">
-            {{FieldStringPrint .doc_entry "title" .LANG}}
+        {{range $i, $v := Breadcrumbs $}}
+        <li class="breadcrumb-item{{if $v.Active}} active{{end}}">
+          {{if eq $i 0}}<span class="icon"><i class="fas fa-file-alt"></i></span>{{end}}
+          {{if $v.Href}}
+          <a href="{{$v.Href}}{{if lt $i 2}}/{{end}}"{{if eq $i 0}} style="margin-left: 10px"{{end}}>
+            <span>{{if eq $i 0}}{{T $.LANG "Documents"}}{{else}}{{$v.Label}}{{end}}</span>
           </a>
+          {{else}}
+          <span{{if eq $i 0}} style="margin-left: 10px"{{end}}>{{if eq $i 0}}{{T $.LANG "Documents"}}{{else}}{{$v.Label}}{{end}}</span>
+          {{end}}
         </li>
+        {{end}}
   </ol>
 </nav>
 </div>
@@ -56,12 +58,12 @@
 </div>
 </div>
 
-{{pagelet . "core/general" "v3/footer.tpl"}}
+{{pagelet . "core/general" "v3/footer.tpl" "cache_ttl=600"}}
 
 
 <script type="text/javascript">
 window.onload_hooks.push(function() {
-    hp.CodeRender({"theme": "monokai"});
+    hp.CodeRender({"theme": {{SiteThemeConfig $ "code_theme" "monokai"}}});
 	gdoc.PageEntryRender({
         "doc_base_path": "{{.baseuri}}/view/{{.doc_entry.ExtPermalinkName}}/",
 	});
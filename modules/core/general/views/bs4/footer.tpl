@@ -2,7 +2,7 @@
 <div class="container">
   <div class="row">
     <div class="col">
-      &copy; {{SysConfig "frontend_footer_copyright"}}
+      &copy; {{SiteConfig $ "frontend_footer_copyright"}}
     </div>
 	<div class="col-md-auto">
       <span class="hp-footer-powerby-item">Published by <strong><a href="https://github.com/hooto/hpress" target="_blank">Hooto Press CMS</a></strong>,</span>
@@ -20,4 +20,4 @@
   </div>
 </div>
 </footer>
-{{raw (SysConfig "frontend_footer_analytics_scripts")}}
+{{raw (SiteConfig $ "frontend_footer_analytics_scripts")}}
@@ -1,17 +1,23 @@
 <nav class="navbar navbar-expand navbar-light bg-light" style="sbackground-color: #1890FF;" id="hp-topbar">
 <div class="container">
-  {{if SysConfig "frontend_header_site_logo_url"}}
+  {{if SiteConfig $ "frontend_header_site_logo_url"}}
   <span class="navbar-brand">
-    <img src="{{SysConfig "frontend_header_site_logo_url"}}" height="30" alt="">
+    <img src="{{SiteConfig $ "frontend_header_site_logo_url"}}" height="30" alt="">
   </span>
   {{end}}
-  <span class="navbar-brand mb-0 h1">{{SysConfig "frontend_header_site_name"}}</span>
+  <span class="navbar-brand mb-0 h1">{{SiteConfig $ "frontend_header_site_name"}}</span>
 
   <div class="collapse navbar-collapse" id="hpex-topbar-nav-main">
     <ul class="navbar-nav mr-auto" id="hp-topbar-nav-main">
+      {{with Menu $ "main"}}
+      {{range $v := .}}
+      <li class="nav-item{{if $v.Active}} active{{else if $v.Trail}} active-trail{{end}}"><a class="nav-link" href="{{$v.Href}}">{{$v.Label}}</a></li>
+      {{end}}
+      {{else}}
       {{range $v := .topnav.Items}}
       <li class="nav-item"><a class="nav-link" href="{{FieldString $v.Fields "url"}}">{{FieldStringPrint $v "title" $.LANG}}</a></li>
       {{end}}
+      {{end}}
     </ul>
     <ul class="navbar-nav hp-nav-right">
       {{if $.frontend_langs}}
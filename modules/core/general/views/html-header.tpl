@@ -1,11 +1,16 @@
 <head>
   <meta charset="utf-8">
-  <title>{{if .__html_head_title__}}{{.__html_head_title__}} | {{end}}{{SysConfig "frontend_html_head_subtitle"}}</title>
+  <title>{{if .__html_head_title__}}{{.__html_head_title__}} | {{end}}{{SiteConfig $ "frontend_html_head_subtitle"}}</title>
   <link rel="stylesheet" href="{{HttpSrvBasePath "hp/~/bs/3.3/css/bootstrap.css"}}?v={{.sys_version_sign}}" type="text/css">
   <link rel="stylesheet" href="{{HttpSrvBasePath "hp/~/hp/css/main.css"}}?v={{.sys_version_sign}}" type="text/css">
+  {{with SiteThemeConfig $ "color_primary"}}<style>:root { --hp-color-primary: {{.}}; }</style>{{end}}
   <link rel="shortcut icon" type="image/x-icon" href="{{HttpSrvBasePath "hp/~/hp/img/ap.ico"}}?v={{.sys_version_sign}}">
-  <meta name="keywords" content="{{SysConfig "frontend_html_head_meta_keywords"}}">
-  <meta name="description" content="{{SysConfig "frontend_html_head_meta_description"}}">
+  {{if .__html_head_seo__}}
+  {{.__html_head_seo__}}
+  {{else}}
+  <meta name="keywords" content="{{SiteConfig $ "frontend_html_head_meta_keywords"}}">
+  <meta name="description" content="{{SiteConfig $ "frontend_html_head_meta_description"}}">
+  {{end}}
   <script src="{{HttpSrvBasePath "hp/~/lessui/js/sea.js"}}?v={{.sys_version_sign}}"></script>
   <script src="{{HttpSrvBasePath "hp/~/hp/js/main.js"}}?v={{.sys_version_sign}}"></script>
   <script type="text/javascript">
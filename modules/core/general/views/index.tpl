@@ -16,7 +16,7 @@
   </div>
 </div>
 
-{{pagelet . "core/general" "footer.tpl"}}
+{{pagelet . "core/general" "footer.tpl" "cache_ttl=600"}}
 
 {{pagelet . "core/general" "html-footer.tpl"}}
 </body>
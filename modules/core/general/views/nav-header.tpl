@@ -1,16 +1,22 @@
 <div id="hp-topbar">
   <div class="container hp-topbar-collapse">
     <ul class="hp-nav">
-      {{if SysConfig "frontend_header_site_logo_url"}}
-      <li><img class="hp-topbar-logo" src="{{SysConfig "frontend_header_site_logo_url"}}" height="30"></li>
+      {{if SiteConfig $ "frontend_header_site_logo_url"}}
+      <li><img class="hp-topbar-logo" src="{{SiteConfig $ "frontend_header_site_logo_url"}}" height="30"></li>
       {{end}}
-      <li class="hp-topbar-brand">{{SysConfig "frontend_header_site_name"}}</li>
+      <li class="hp-topbar-brand">{{SiteConfig $ "frontend_header_site_name"}}</li>
     </ul>
 
     <ul class="hp-nav hp-topbar-nav" id="hp-topbar-nav-main">
+      {{with Menu $ "main"}}
+      {{range $v := .}}
+      <li class="nav-item{{if $v.Active}} active{{else if $v.Trail}} active-trail{{end}}"><a class="nav-link" href="{{$v.Href}}">{{$v.Label}}</a></li>
+      {{end}}
+      {{else}}
       {{range $v := .topnav.Items}}
       <li class="nav-item"><a class="nav-link" href="{{FieldString $v.Fields "url"}}">{{FieldStringPrint $v "title" $.LANG}}</a></li>
       {{end}}
+      {{end}}
     </ul>
 
     <ul class="hp-nav hp-nav-right" id="hp-topbar-userbar"></ul>
@@ -0,0 +1,83 @@
+<!DOCTYPE html>
+<html lang="en">
+{{pagelet . "core/general" "v2/html-header.tpl"}}
+<body id="hp-body">
+
+{{pagelet . "core/general" "v2/nav-header.tpl" "topnav"}}
+
+<div class="container" style="margin-top:10px">
+
+  <div class="columns">
+    <div class="column is-9">
+      <div class="hp-ctn-title">
+        Search
+      </div>
+    </div>
+    <div class="column is-3">
+      <form action="/search">
+        <div class="field has-addons">
+          <div class="control">
+            <input type="text" class="input"
+              placeholder=""
+              name="qry_text"
+              value="{{.qry_text}}">
+          </div>
+          <div class="control">
+            <input class="button is-dark" type="submit" value="Search">
+          </div>
+        </div>
+      </form>
+    </div>
+  </div>
+</div>
+
+<div class="container">
+
+  {{if .list.SearchSuggest}}
+  <div class="hp-search-suggest">
+    Did you mean <a href="/search?qry_text={{.list.SearchSuggest}}">{{.list.SearchSuggest}}</a> ?
+  </div>
+  {{end}}
+
+  <ul class="hp-node-list">
+    {{range $v := .list.Items}}
+    <li class="hp-node-list-item">
+      <h4 class="hp-node-list-heading">
+        {{if $v.Permalink}}
+        <a href="{{$v.Permalink}}{{if $.list.SearchLogID}}?search_log={{$.list.SearchLogID}}{{end}}">{{if $v.Node.SearchExcerpt}}{{SearchExcerptPrint $v.Node "title"}}{{else}}{{$v.Node.Title}}{{end}}</a>
+        {{else}}
+        {{if $v.Node.SearchExcerpt}}{{SearchExcerptPrint $v.Node "title"}}{{else}}{{$v.Node.Title}}{{end}}
+        {{end}}
+      </h4>
+      <div class="hp-node-list-info">
+        <span class="info-item">{{$v.ModName}}</span>
+        <span class="info-item">
+          Published : {{UnixtimeFormat $v.Node.Created "Y-m-d"}}
+        </span>
+      </div>
+      {{if $v.Node.SearchExcerpt}}
+      <div class="hp-node-list-text">{{SearchExcerptPrint $v.Node "content"}}</div>
+      {{end}}
+    </li>
+    {{end}}
+  </ul>
+
+  {{if .list_pager}}
+  <nav class="pagination is-centered hp-pagination">
+    <ul class="pagination-list">
+    {{range $index, $page := .list_pager.RangePages}}
+    <li>
+      <a class="pagination-link {{if eq $page $.list_pager.CurrentPageNumber}}is-current{{end}}" href="/search?{{FilterUri $ "page" $page}}">{{$page}}</a>
+    </li>
+    {{end}}
+    </ul>
+  </nav>
+  {{end}}
+
+</div>
+
+{{pagelet . "core/general" "v2/footer.tpl" "cache_ttl=600"}}
+
+{{pagelet . "core/general" "html-footer.tpl"}}
+</body>
+</html>
@@ -2,7 +2,7 @@
 <div class="container">
   <div class="columns">
     <div class="column">
-      {{raw (SysConfig "frontend_footer_copyright")}}
+      {{raw (SiteConfig $ "frontend_footer_copyright")}}
     </div>
 	<div class="column">
     </div>
@@ -22,4 +22,4 @@
   </div>
 </div>
 </footer>
-{{raw (SysConfig "frontend_footer_analytics_scripts")}}
+{{raw (SiteConfig $ "frontend_footer_analytics_scripts")}}
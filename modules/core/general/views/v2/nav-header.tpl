@@ -1,10 +1,10 @@
 <nav class="navbar {{.navbar_class}}" id="hp-topbar">
 <div class="container">
   <div class="navbar-brand">
-   {{if SysConfig "frontend_header_site_logo_url"}}
+   {{if SiteConfig $ "frontend_header_site_logo_url"}}
     <a class="navbar-item" href="#">
-      <img src="{{SysConfig "frontend_header_site_logo_url"}}"  height="30" alt="">
-      {{SysConfig "frontend_header_site_name"}}
+      <img src="{{SiteConfig $ "frontend_header_site_logo_url"}}"  height="30" alt="">
+      {{SiteConfig $ "frontend_header_site_name"}}
     </a>
     {{end}}
     <a role="button" class="navbar-burger" onclick="hp.NavbarMenuToggle('hpex-topbar-nav-main')">
@@ -16,9 +16,15 @@
  
   <div class="navbar-menu" id="hpex-topbar-nav-main">
     <div class="navbar-start" id="hp-topbar-nav-main">
+      {{with Menu $ "main"}}
+      {{range $v := .}}
+      <a class="navbar-item is-tab{{if or $v.Active $v.Trail}} is-active{{end}}" href="{{$v.Href}}">{{$v.Label}}</a>
+      {{end}}
+      {{else}}
       {{range $v := .topnav.Items}}
       <a class="navbar-item is-tab" href="{{FieldString $v.Fields "url"}}">{{FieldStringPrint $v "title" $.LANG}}</a>
       {{end}}
+      {{end}}
     </div>
     <div class="navbar-end">
       {{if $.frontend_langs}}
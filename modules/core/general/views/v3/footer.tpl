@@ -1,11 +1,11 @@
-{{if (SysConfig "frontend_html_footer")}}
-{{raw (SysConfig "frontend_html_footer")}}
+{{if (SiteConfig $ "frontend_html_footer")}}
+{{raw (SiteConfig $ "frontend_html_footer")}}
 {{else}}
 <footer class="hp-footer hp-container-full">
 <div class="container">
   <div class="columns">
     <div class="column">
-      {{raw (SysConfig "frontend_footer_copyright")}}
+      {{raw (SiteConfig $ "frontend_footer_copyright")}}
     </div>
 	<div class="column">
     </div>
@@ -26,4 +26,4 @@
 </div>
 </footer>
 {{end}}
-{{raw (SysConfig "frontend_footer_analytics_scripts")}}
+{{raw (SiteConfig $ "frontend_footer_analytics_scripts")}}
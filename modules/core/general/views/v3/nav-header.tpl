@@ -2,10 +2,10 @@
 <div class="container">
 
     <a class="navbar-brand" href="/">
-      {{if SysConfig "frontend_header_site_logo_url"}}
-      <img src="{{SysConfig "frontend_header_site_logo_url"}}"  height="40" alt="">
+      {{if SiteConfig $ "frontend_header_site_logo_url"}}
+      <img src="{{SiteConfig $ "frontend_header_site_logo_url"}}"  height="40" alt="">
       {{end}}
-      {{SysConfig "frontend_header_site_name"}}
+      {{SiteConfig $ "frontend_header_site_name"}}
     </a>
 
     <button class="navbar-toggler" type="button" data-bs-toggle="collapse" data-bs-target="#hp-topbar-navbar-toggler">
@@ -14,11 +14,19 @@
 
     <div class="collapse navbar-collapse" id="hp-topbar-navbar-toggler">
       <ul class="navbar-nav me-auto" id="hp-topbar-nav-main">
+        {{with Menu $ "main"}}
+        {{range $v := .}}
+        <li class="nav-item col-6 col-md-auto ml-2 mr-2">
+          <a class="nav-link is-tab{{if or $v.Active $v.Trail}} active{{end}}" href="{{$v.Href}}">{{$v.Label}}</a>
+        </li>
+        {{end}}
+        {{else}}
         {{range $v := .topnav.Items}}
         <li class="nav-item col-6 col-md-auto ml-2 mr-2">
           <a class="nav-link is-tab" href="{{FieldString $v.Fields "url"}}">{{FieldStringPrint $v "title" $.LANG}}</a>
         </li>
         {{end}}
+        {{end}}
       </ul>
       <hr class="d-mode-none text-width-50">
       <ul class="navbar-nav d-flex">
@@ -9,7 +9,7 @@
 
 {{FieldHtmlPrint .page_entry "content" .LANG}}
 
-{{pagelet . "core/general" "v3/footer.tpl"}}
+{{pagelet . "core/general" "v3/footer.tpl" "cache_ttl=600"}}
 
 {{pagelet . "core/general" "html-footer.tpl"}}
 </body>
@@ -0,0 +1,335 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"encoding/xml"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+const (
+	feedSummaryLength = 300
+)
+
+var (
+	feedLinkReg = regexp.MustCompile(`(href|src)="/([^/"])`)
+)
+
+type feedCDATA struct {
+	Text string `xml:",cdata"`
+}
+
+type feedRss struct {
+	XMLName xml.Name       `xml:"rss"`
+	Version string         `xml:"version,attr"`
+	AtomNS  string         `xml:"xmlns:atom,attr"`
+	Channel feedRssChannel `xml:"channel"`
+}
+
+type feedRssChannel struct {
+	Title         string        `xml:"title"`
+	Link          string        `xml:"link"`
+	Description   string        `xml:"description"`
+	Language      string        `xml:"language,omitempty"`
+	LastBuildDate string        `xml:"lastBuildDate,omitempty"`
+	AtomLink      feedAtomLink  `xml:"atom:link"`
+	Items         []feedRssItem `xml:"item"`
+}
+
+type feedRssItem struct {
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	Guid        feedRssGuid `xml:"guid"`
+	PubDate     string      `xml:"pubDate"`
+	Categories  []string    `xml:"category"`
+	Description feedCDATA   `xml:"description"`
+}
+
+type feedRssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type feedAtom struct {
+	XMLName xml.Name        `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string          `xml:"title"`
+	ID      string          `xml:"id"`
+	Updated string          `xml:"updated"`
+	Links   []feedAtomLink  `xml:"link"`
+	Entries []feedAtomEntry `xml:"entry"`
+}
+
+type feedAtomLink struct {
+	Href     string `xml:"href,attr"`
+	Rel      string `xml:"rel,attr,omitempty"`
+	Type     string `xml:"type,attr,omitempty"`
+	HrefLang string `xml:"hreflang,attr,omitempty"`
+}
+
+type feedAtomEntry struct {
+	Title      string             `xml:"title"`
+	ID         string             `xml:"id"`
+	Links      []feedAtomLink     `xml:"link"`
+	Published  string             `xml:"published"`
+	Updated    string             `xml:"updated"`
+	Categories []feedAtomCategory `xml:"category"`
+	Summary    *feedAtomText      `xml:"summary,omitempty"`
+	Content    *feedAtomText      `xml:"content,omitempty"`
+}
+
+type feedAtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type feedAtomText struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedSpec returns the feed of the request, a feed route or any route of a
+// node.list with the feed=rss|atom param.
+func (c *Index) feedSpec(route *api.Route) *api.RouteFeed {
+
+	if route != nil && route.Feed != nil {
+		return route.Feed
+	}
+
+	if v := c.Params.Get("feed"); v == api.RouteFeedRss || v == api.RouteFeedAtom {
+		return &api.RouteFeed{
+			Format: v,
+		}
+	}
+
+	return nil
+}
+
+// hostUrl returns the scheme and host of the request, e.g. https://example.com
+func (c *Index) hostUrl() string {
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	} else if v := c.Request.Header.Get("X-Forwarded-Proto"); v == "https" {
+		scheme = v
+	}
+
+	return scheme + "://" + c.Request.Host
+}
+
+// siteUrl returns the absolute url of the site base path
+func (c *Index) siteUrl() string {
+	return strings.TrimRight(c.hostUrl()+config.HttpSrvBasePath(""), "/")
+}
+
+func (c *Index) feedRender(mod *api.Spec, dataAction string, feed *api.RouteFeed) bool {
+
+	var (
+		ls    *api.NodeList
+		table string
+	)
+
+	for _, action := range mod.Actions {
+		if action.Name != dataAction {
+			continue
+		}
+		for _, ad := range action.Datax {
+			if ad.Type != "node.list" {
+				continue
+			}
+			if v, ok := c.Data[ad.Name].(api.NodeList); ok {
+				ls, table = &v, ad.Query.Table
+			}
+			break
+		}
+		break
+	}
+
+	if ls == nil {
+		return false
+	}
+
+	lang, _ := c.Data["LANG"].(string)
+
+	var (
+		host     = c.hostUrl()
+		site     = c.siteUrl()
+		siteName = c.sysConfig("frontend_header_site_name")
+		title    = mod.Title
+		selfLink = host + c.Request.URL.RequestURI()
+		modLink  = site + "/" + mod.SrvName
+		column   = ""
+		updated  = uint32(0)
+	)
+
+	if siteName != "" {
+		title = siteName + " - " + title
+	}
+
+	if model, err := config.SpecNodeModel(mod.Meta.Name, table); err == nil {
+		for _, field := range model.Fields {
+			if field.Type == "text" {
+				column = field.Name
+				break
+			}
+		}
+	}
+
+	type feedItem struct {
+		title      string
+		link       string
+		created    time.Time
+		updated    time.Time
+		categories []string
+		body       string
+	}
+
+	items := []feedItem{}
+
+	for _, node := range ls.Items {
+
+		item := feedItem{
+			title:   datax.FieldStringPrint(node, "title", lang),
+			link:    datax.NodePermalink(mod, table, &node),
+			created: time.Unix(int64(node.Created), 0),
+			updated: time.Unix(int64(node.Updated), 0),
+		}
+
+		if item.link == "" {
+			continue
+		}
+		item.link = site + item.link
+
+		if node.Updated > updated {
+			updated = node.Updated
+		}
+
+		for _, term := range node.Terms {
+			for _, v := range term.Items {
+				if v.Title != "" {
+					item.categories = append(item.categories, v.Title)
+				}
+			}
+		}
+
+		if column != "" {
+			if feed.Content == api.RouteFeedFullContent {
+				item.body = string(datax.FieldHtmlPrint(node, column, lang))
+			} else {
+				item.body = string(datax.FieldHtmlSubPrint(node, column, feedSummaryLength, lang))
+			}
+			item.body = feedLinkReg.ReplaceAllString(item.body, `$1="`+host+`/$2`)
+		}
+
+		items = append(items, item)
+	}
+
+	var (
+		doc   interface{}
+		ctype string
+	)
+
+	switch feed.Format {
+
+	case api.RouteFeedAtom:
+
+		atom := feedAtom{
+			Title:   title,
+			ID:      modLink,
+			Updated: time.Unix(int64(updated), 0).UTC().Format(time.RFC3339),
+			Links: []feedAtomLink{
+				{Href: selfLink, Rel: "self", Type: "application/atom+xml"},
+				{Href: modLink, Rel: "alternate", Type: "text/html", HrefLang: lang},
+			},
+		}
+
+		for _, item := range items {
+			entry := feedAtomEntry{
+				Title:     item.title,
+				ID:        item.link,
+				Links:     []feedAtomLink{{Href: item.link, Rel: "alternate", Type: "text/html"}},
+				Published: item.created.UTC().Format(time.RFC3339),
+				Updated:   item.updated.UTC().Format(time.RFC3339),
+			}
+			for _, v := range item.categories {
+				entry.Categories = append(entry.Categories, feedAtomCategory{Term: v})
+			}
+			text := &feedAtomText{Type: "html", Body: item.body}
+			if feed.Content == api.RouteFeedFullContent {
+				entry.Content = text
+			} else {
+				entry.Summary = text
+			}
+			atom.Entries = append(atom.Entries, entry)
+		}
+
+		doc, ctype = atom, "application/atom+xml; charset=utf-8"
+
+	default:
+
+		rss := feedRss{
+			Version: "2.0",
+			AtomNS:  "http://www.w3.org/2005/Atom",
+			Channel: feedRssChannel{
+				Title:       title,
+				Link:        modLink,
+				Description: c.sysConfig("frontend_html_head_meta_description"),
+				Language:    lang,
+				AtomLink: feedAtomLink{
+					Href: selfLink,
+					Rel:  "self",
+					Type: "application/rss+xml",
+				},
+			},
+		}
+		if rss.Channel.Description == "" {
+			rss.Channel.Description = title
+		}
+		if updated > 0 {
+			rss.Channel.LastBuildDate = time.Unix(int64(updated), 0).Format(time.RFC1123Z)
+		}
+
+		for _, item := range items {
+			rss.Channel.Items = append(rss.Channel.Items, feedRssItem{
+				Title:       item.title,
+				Link:        item.link,
+				Guid:        feedRssGuid{IsPermaLink: true, Value: item.link},
+				PubDate:     item.created.Format(time.RFC1123Z),
+				Categories:  item.categories,
+				Description: feedCDATA{Text: item.body},
+			})
+		}
+
+		doc, ctype = rss, "application/rss+xml; charset=utf-8"
+	}
+
+	bs, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		c.RenderError(http.StatusInternalServerError, err.Error())
+		return true
+	}
+
+	c.Response.Out.Header().Set("Content-Type", ctype)
+	c.Response.Out.Write([]byte(xml.Header))
+	c.Response.Out.Write(bs)
+
+	return true
+}
@@ -0,0 +1,128 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestFeedRssXml(t *testing.T) {
+
+	rss := feedRss{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: feedRssChannel{
+			Title:       "Blog <News>",
+			Link:        "http://example.com/blog",
+			Description: "Blog",
+			AtomLink: feedAtomLink{
+				Href: "http://example.com/blog/feed?a=1&b=2",
+				Rel:  "self",
+				Type: "application/rss+xml",
+			},
+			Items: []feedRssItem{{
+				Title:       "A & B",
+				Link:        "http://example.com/blog/a.html",
+				Guid:        feedRssGuid{IsPermaLink: true, Value: "http://example.com/blog/a.html"},
+				Categories:  []string{"go", "web"},
+				Description: feedCDATA{Text: "<p>x]]>y</p>"},
+			}},
+		},
+	}
+
+	bs, err := xml.Marshal(rss)
+	if err != nil {
+		t.Fatalf("Failed on Marshal, err %s", err.Error())
+	}
+	s := string(bs)
+
+	for _, v := range []string{
+		`<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">`,
+		`<title>Blog &lt;News&gt;</title>`,
+		`<atom:link href="http://example.com/blog/feed?a=1&amp;b=2" rel="self" type="application/rss+xml">`,
+		`<guid isPermaLink="true">http://example.com/blog/a.html</guid>`,
+		`<category>go</category><category>web</category>`,
+		`<description><![CDATA[<p>x]]]]><![CDATA[>y</p>]]></description>`,
+	} {
+		if !strings.Contains(s, v) {
+			t.Fatalf("Failed on RSS, expect %s, got %s", v, s)
+		}
+	}
+
+	var item struct {
+		Description string `xml:"channel>item>description"`
+	}
+	if err := xml.Unmarshal(bs, &item); err != nil {
+		t.Fatalf("Failed on Unmarshal, err %s", err.Error())
+	}
+	if item.Description != "<p>x]]>y</p>" {
+		t.Fatalf("Failed on RSS Description, expect %s, got %s", "<p>x]]>y</p>", item.Description)
+	}
+}
+
+func TestFeedAtomXml(t *testing.T) {
+
+	atom := feedAtom{
+		Title:   "Blog",
+		ID:      "http://example.com/blog",
+		Updated: "2019-01-02T03:04:05Z",
+		Links: []feedAtomLink{
+			{Href: "http://example.com/blog/feed", Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: []feedAtomEntry{{
+			Title:      "A",
+			ID:         "http://example.com/blog/a.html",
+			Categories: []feedAtomCategory{{Term: "go"}},
+			Summary:    &feedAtomText{Type: "html", Body: "<p>x</p>"},
+		}},
+	}
+
+	bs, err := xml.Marshal(atom)
+	if err != nil {
+		t.Fatalf("Failed on Marshal, err %s", err.Error())
+	}
+	s := string(bs)
+
+	for _, v := range []string{
+		`<feed xmlns="http://www.w3.org/2005/Atom">`,
+		`<link href="http://example.com/blog/feed" rel="self" type="application/atom+xml">`,
+		`<category term="go">`,
+		`<summary type="html">&lt;p&gt;x&lt;/p&gt;</summary>`,
+	} {
+		if !strings.Contains(s, v) {
+			t.Fatalf("Failed on Atom, expect %s, got %s", v, s)
+		}
+	}
+
+	if strings.Contains(s, "<content") {
+		t.Fatalf("Failed on Atom, the empty content is not omitted, got %s", s)
+	}
+}
+
+func TestFeedLinkReg(t *testing.T) {
+
+	for _, v := range [][2]string{
+		{`<a href="/blog/a.html">`, `<a href="http://example.com/blog/a.html">`},
+		{`<img src="/s/a.png">`, `<img src="http://example.com/s/a.png">`},
+		{`<img src="//cdn.example.com/a.png">`, `<img src="//cdn.example.com/a.png">`},
+		{`<a href="https://example.org/">`, `<a href="https://example.org/">`},
+	} {
+		if s := feedLinkReg.ReplaceAllString(v[0], `$1="http://example.com/$2`); s != v[1] {
+			t.Fatalf("Failed on LinkReg %s, expect %s, got %s", v[0], v[1], s)
+		}
+	}
+}
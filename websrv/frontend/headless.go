@@ -0,0 +1,213 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"strings"
+
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+const (
+	headlessSuffix = ".json"
+)
+
+type headlessPage struct {
+	types.TypeMeta `json:",inline"`
+	Route          *headlessRoute         `json:"route,omitempty"`
+	Params         map[string]string      `json:"params,omitempty"`
+	Lang           string                 `json:"lang,omitempty"`
+	Data           map[string]interface{} `json:"data"`
+	Pagers         map[string]interface{} `json:"pagers,omitempty"`
+}
+
+type headlessRoute struct {
+	SrvName    string `json:"srvname"`
+	ModName    string `json:"modname"`
+	Path       string `json:"path,omitempty"`
+	DataAction string `json:"dataAction,omitempty"`
+	Template   string `json:"template,omitempty"`
+}
+
+// headlessNode is the public view of a node, the fields are limited to the
+// defined and not private fields of the model, and the text fields come
+// with the rendered html.
+type headlessNode struct {
+	ID               string                 `json:"id"`
+	SelfLink         string                 `json:"self_link,omitempty"`
+	Title            string                 `json:"title,omitempty"`
+	UserID           string                 `json:"userid,omitempty"`
+	Created          uint32                 `json:"created,omitempty"`
+	Updated          uint32                 `json:"updated,omitempty"`
+	Fields           []*api.NodeField       `json:"fields,omitempty"`
+	Terms            []api.NodeTerm         `json:"terms,omitempty"`
+	ExtAccessCounter uint32                 `json:"ext_access_counter,omitempty"`
+	ExtPermalinkName string                 `json:"ext_permalink_name,omitempty"`
+	ExtNodeRefer     string                 `json:"ext_node_refer,omitempty"`
+	SearchExcerpt    *api.NodeSearchExcerpt `json:"search_excerpt,omitempty"`
+	SearchScore      int64                  `json:"search_score,omitempty"`
+	Html             map[string]string      `json:"html,omitempty"`
+}
+
+type headlessNodeList struct {
+	types.TypeMeta `json:",inline"`
+	Meta           types.ListMeta       `json:"meta,omitempty"`
+	Items          []headlessNode       `json:"items,omitempty"`
+	Facets         []*api.NodeListFacet `json:"facets,omitempty"`
+	SearchSuggest  string               `json:"search_suggest,omitempty"`
+}
+
+// headlessMode returns true if the request asks for the page data as JSON,
+// by the .json suffix of the path or by the Accept header
+func (c *Index) headlessMode(reqpath string) (string, bool) {
+
+	if strings.HasSuffix(reqpath, headlessSuffix) {
+		reqpath = strings.TrimSuffix(reqpath, headlessSuffix)
+		if reqpath == "" {
+			reqpath = "/"
+		}
+		return reqpath, true
+	}
+
+	accept := c.Request.Header.Get("Accept")
+	if strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html") {
+		return reqpath, true
+	}
+
+	return reqpath, false
+}
+
+func headlessNodeEntry(node api.Node, model *api.NodeModel, lang string) headlessNode {
+
+	item := headlessNode{
+		ID:               node.ID,
+		SelfLink:         node.SelfLink,
+		Title:            node.Title,
+		UserID:           node.UserID,
+		Created:          node.Created,
+		Updated:          node.Updated,
+		Terms:            node.Terms,
+		ExtAccessCounter: node.ExtAccessCounter,
+		ExtPermalinkName: node.ExtPermalinkName,
+		ExtNodeRefer:     node.ExtNodeRefer,
+		SearchExcerpt:    node.SearchExcerpt,
+		SearchScore:      node.SearchScore,
+	}
+
+	if model == nil {
+		return item
+	}
+
+	for _, field := range node.Fields {
+
+		fm := model.Field(field.Name)
+		if fm == nil || fm.Private() {
+			continue
+		}
+		item.Fields = append(item.Fields, field)
+
+		if fm.Type != "text" {
+			continue
+		}
+		if item.Html == nil {
+			item.Html = map[string]string{}
+		}
+		item.Html[field.Name] = string(datax.FieldHtmlPrint(node, field.Name, lang))
+	}
+
+	return item
+}
+
+// headlessRender writes the route, params and the datax results of the
+// page as JSON
+func (c *Index) headlessRender(srvname string, mod *api.Spec, route *api.Route, datas []api.ActionData) {
+
+	lang, _ := c.Data["LANG"].(string)
+
+	page := headlessPage{
+		Route: &headlessRoute{
+			SrvName: srvname,
+			ModName: mod.Meta.Name,
+		},
+		Params: map[string]string{},
+		Lang:   lang,
+		Data:   map[string]interface{}{},
+	}
+	page.Kind = "FrontendPage"
+
+	if route != nil {
+		page.Route.Path = route.Path
+		page.Route.DataAction = route.DataAction
+		page.Route.Template = route.Template
+	}
+
+	for k, vs := range c.Params.Values {
+		if len(vs) > 0 && k != "hp_preview" {
+			page.Params[k] = vs[0]
+		}
+	}
+
+	for _, ad := range datas {
+
+		v, ok := c.Data[ad.Name]
+		if !ok {
+			continue
+		}
+
+		var model *api.NodeModel
+		if strings.HasPrefix(ad.Type, "node.") {
+			model, _ = config.SpecNodeModel(mod.Meta.Name, ad.Query.Table)
+		}
+
+		switch dv := v.(type) {
+
+		case api.Node:
+			page.Data[ad.Name] = headlessNodeEntry(dv, model, lang)
+
+		case api.NodeList:
+			ls := headlessNodeList{
+				TypeMeta:      dv.TypeMeta,
+				Meta:          dv.Meta,
+				Facets:        dv.Facets,
+				SearchSuggest: dv.SearchSuggest,
+			}
+			for _, node := range dv.Items {
+				ls.Items = append(ls.Items, headlessNodeEntry(node, model, lang))
+			}
+			page.Data[ad.Name] = ls
+
+		default:
+			page.Data[ad.Name] = v
+		}
+
+		if pager, ok := c.Data[ad.Name+"_pager"]; ok {
+			if page.Pagers == nil {
+				page.Pagers = map[string]interface{}{}
+			}
+			page.Pagers[ad.Name] = pager
+		}
+	}
+
+	c.RenderJson(&page)
+}
+
+func (c *Index) headlessError(code int, msg string) {
+	c.Response.Out.WriteHeader(code)
+	c.RenderJson(types.NewTypeErrorMeta(api.ErrCodeNotFound, msg))
+}
@@ -0,0 +1,75 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+)
+
+func TestHeadlessNodePrivateFields(t *testing.T) {
+
+	model := &api.NodeModel{
+		Fields: []api.FieldModel{
+			{Name: "title", Type: "string"},
+			{Name: "repo_url", Type: "string"},
+			{Name: "repo_auth_user", Type: "string", Attrs: types.KvPairs{{Key: "private", Value: "true"}}},
+			{Name: "repo_auth_key", Type: "string", Attrs: types.KvPairs{{Key: "private", Value: "true"}}},
+		},
+	}
+
+	node := api.Node{
+		ID:    "0123456789ab",
+		Title: "Doc",
+		Model: model,
+		Fields: []*api.NodeField{
+			{Name: "title", Value: "Doc"},
+			{Name: "repo_url", Value: "https://example.com/doc.git"},
+			{Name: "repo_auth_user", Value: "secret-user"},
+			{Name: "repo_auth_key", Value: "secret-key"},
+			{Name: "undefined", Value: "secret-undefined"},
+		},
+	}
+
+	ls := headlessNodeList{}
+	ls.Items = append(ls.Items, headlessNodeEntry(node, model, "en-us"))
+
+	for _, v := range []interface{}{
+		headlessNodeEntry(node, model, "en-us"),
+		ls,
+		headlessNodeEntry(node, nil, "en-us"),
+	} {
+
+		bs, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, secret := range []string{"secret-user", "secret-key", "secret-undefined", "repo_auth"} {
+			if strings.Contains(string(bs), secret) {
+				t.Fatalf("Failed on private field %s, got %s", secret, string(bs))
+			}
+		}
+	}
+
+	if v := headlessNodeEntry(node, model, "en-us"); len(v.Fields) != 2 {
+		t.Fatalf("Failed on public fields, expect 2, got %d", len(v.Fields))
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+// httpValidator collects the nodes and terms of a page to build the ETag
+// and Last-Modified validators, and the tags of the page output cache
+type httpValidator struct {
+	items    []string
+	modified uint32
+	tags     types.ArrayString
+}
+
+func (it *httpValidator) add(kind, id string, updated uint32) {
+	it.items = append(it.items, kind+":"+id+":"+strconv.FormatUint(uint64(updated), 10))
+	if updated > it.modified {
+		it.modified = updated
+	}
+}
+
+func (it *httpValidator) node(modname, model string, node *api.Node) {
+	it.add("node", node.ID, node.Updated)
+	it.tags.Set(datax.PageCacheTagNode(modname, model, node.ID))
+}
+
+func (it *httpValidator) nodeList(modname, model, name string, ls *api.NodeList) {
+	it.items = append(it.items, "nodes:"+name+":"+strconv.FormatUint(ls.Meta.TotalResults, 10))
+	for _, v := range ls.Items {
+		it.add("node", v.ID, v.Updated)
+	}
+	it.tags.Set(datax.PageCacheTagNodes(modname, model))
+}
+
+func (it *httpValidator) term(modname, model string, term *api.Term) {
+	it.add("term", strconv.FormatUint(uint64(term.ID), 10), term.Updated)
+	it.tags.Set(datax.PageCacheTagTerm(modname, model, term.ID))
+}
+
+func (it *httpValidator) termList(modname, model, name string, ls *api.TermList) {
+	it.items = append(it.items, "terms:"+name+":"+strconv.FormatUint(ls.Meta.TotalResults, 10))
+	for _, v := range ls.Items {
+		it.add("term", strconv.FormatUint(uint64(v.ID), 10), v.Updated)
+	}
+	it.tags.Set(datax.PageCacheTagTerms(modname, model))
+}
+
+// httpCacheCheck sets the cache headers of the page, and answers 304 if the
+// validators of the conditional request still match.
+func (c *Index) httpCacheCheck(route *api.Route, modname, template string) bool {
+
+	var (
+		hdr    = c.Response.Out.Header()
+		policy = api.RouteCache{}
+	)
+
+	if route != nil && route.Cache != nil {
+		policy = *route.Cache
+	}
+
+	if policy.Scope == api.RouteCacheNoStore || c.preview != nil {
+		hdr.Set("Cache-Control", "no-store")
+		return false
+	}
+
+	var (
+		modified = c.validator.modified
+		tplMtime = httpViewsModified()
+		items    = append([]string{}, c.validator.items...)
+	)
+
+	if uint32(tplMtime) > modified {
+		modified = uint32(tplMtime)
+	}
+
+	// the pagelets and menus of the template query their own data, the
+	// versions of their tags and of the lists of the page, which are bumped
+	// by any change including deletes, are part of the validators.
+	tags, tagsKnown := httpTemplateTagsGet(modname, template)
+	if c.headless {
+		tags, tagsKnown = nil, true
+	}
+	tags = append(append([]string{}, tags...), c.validator.tags...)
+	for _, tag := range tags {
+		v := datax.PageCacheTagVersion(tag)
+		items = append(items, "tag:"+tag+":"+strconv.FormatUint(uint64(v), 10))
+		if v > modified {
+			modified = v
+		}
+	}
+
+	h := md5.New()
+	h.Write([]byte(config.SysVersionSign))
+	h.Write([]byte(fmt.Sprintf("\n%s/%s:%d\n%v\n", modname, template, tplMtime, c.Data["LANG"])))
+	if c.us.IsLogin() {
+		h.Write([]byte(c.us.UserName + "\n"))
+	}
+	if c.headless {
+		h.Write([]byte("json\n"))
+	}
+	h.Write([]byte(strings.Join(items, "\n")))
+
+	etag := `"` + hex.EncodeToString(h.Sum(nil))[:24] + `"`
+
+	hdr.Set("ETag", etag)
+	hdr.Set("Vary", "Accept")
+	if modified > 0 {
+		hdr.Set("Last-Modified", time.Unix(int64(modified), 0).UTC().Format(http.TimeFormat))
+	}
+
+	switch {
+
+	case c.us.IsLogin():
+		hdr.Set("Cache-Control", "private, no-cache")
+
+	case policy.Scope == "" && policy.MaxAge == 0:
+		hdr.Set("Cache-Control", "no-cache")
+
+	default:
+		if policy.Scope == "" {
+			policy.Scope = api.RouteCachePublic
+		}
+		hdr.Set("Cache-Control", fmt.Sprintf("%s, max-age=%d", policy.Scope, policy.MaxAge))
+	}
+
+	// the tags of the template are not known until it is rendered once
+	if !tagsKnown {
+		return false
+	}
+
+	if inm := c.Request.Header.Get("If-None-Match"); inm != "" {
+		if !httpETagMatch(inm, etag) {
+			return false
+		}
+	} else if ims := c.Request.Header.Get("If-Modified-Since"); ims != "" && modified > 0 {
+		t, err := http.ParseTime(ims)
+		if err != nil || int64(modified) > t.Unix() {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	c.Response.Out.WriteHeader(http.StatusNotModified)
+
+	return true
+}
+
+func httpETagMatch(inm, etag string) bool {
+	for _, v := range strings.Split(inm, ",") {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+		if v == etag || v == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	httpTemplateMu      sync.RWMutex
+	httpTemplateTags    = map[string][]string{}
+	httpViewsMu         sync.Mutex
+	httpViewsMtime      int64
+	httpViewsChecked    int64
+	httpViewsCheckRange = int64(10)
+)
+
+func httpTemplateTagsGet(modname, template string) ([]string, bool) {
+	httpTemplateMu.RLock()
+	defer httpTemplateMu.RUnlock()
+	tags, ok := httpTemplateTags[modname+"/"+template]
+	return tags, ok
+}
+
+// httpTemplateTagsSet keeps the tags which are added to the page data by
+// the pagelets and menus of the template while it is rendered.
+func (c *Index) httpTemplateTagsSet(modname, template string) {
+	tags := append([]string{}, datax.PageCacheDataTags(c.Data)...)
+	httpTemplateMu.Lock()
+	httpTemplateTags[modname+"/"+template] = tags
+	httpTemplateMu.Unlock()
+}
+
+// httpViewsModified returns the last modified time of the templates of all
+// modules, a page includes the templates of other modules, e.g. the header
+// and footer of core/general.
+func httpViewsModified() int64 {
+
+	httpViewsMu.Lock()
+	defer httpViewsMu.Unlock()
+
+	tn := time.Now().Unix()
+	if httpViewsChecked+httpViewsCheckRange > tn {
+		return httpViewsMtime
+	}
+	httpViewsChecked = tn
+
+	for _, mod := range config.Modules {
+		filepath.Walk(fmt.Sprintf("%s/modules/%s/views", config.Prefix, mod.Meta.Name),
+			func(path string, info os.FileInfo, err error) error {
+				if err == nil && info.ModTime().Unix() > httpViewsMtime {
+					httpViewsMtime = info.ModTime().Unix()
+				}
+				return nil
+			})
+	}
+
+	return httpViewsMtime
+}
@@ -26,6 +26,7 @@ func NewModule() httpsrv.Module {
 	module := httpsrv.NewModule("default")
 
 	module.ControllerRegister(new(Index))
+	module.ControllerRegister(new(Search))
 	module.ControllerRegister(new(Error))
 
 	return module
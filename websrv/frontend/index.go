@@ -16,7 +16,9 @@ package frontend
 
 import (
 	"fmt"
+	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -37,6 +39,11 @@ type Index struct {
 	*httpsrv.Controller
 	hookPosts []func()
 	us        iamapi.UserSession
+	validator httpValidator
+	site      *api.Site
+	preview   *datax.PreviewClaim
+	headless  bool
+	counters  []string
 }
 
 func (c *Index) Init() int {
@@ -44,47 +51,33 @@ func (c *Index) Init() int {
 	return 0
 }
 
-func (c Index) filter(rt []string, spec *api.Spec) (string, string, bool) {
+func (c Index) filter(rt []string, spec *api.Spec) *api.Route {
 
-	for _, route := range spec.Router.Routes {
+	routes := spec.Router.Routes
 
-		matlen, params := 0, map[string]string{}
+	for i := range routes {
 
-		for i, node := range route.Tree {
+		route := &routes[i]
 
-			if len(node) < 1 || i >= len(rt) {
-				break
-			}
-
-			if node[0] == ':' {
-
-				params[node[1:]] = rt[i]
-
-				matlen++
-
-			} else if node == rt[i] {
-
-				matlen++
-			}
+		params, ok := route.Match(rt)
+		if !ok {
+			continue
 		}
 
-		if matlen == len(route.Tree) {
-
-			for k, v := range params {
-				c.Params.Values[k] = append(c.Params.Values[k], v)
-			}
-
-			return route.DataAction, route.Template, true
+		for k, v := range params {
+			c.Params.Values[k] = append(c.Params.Values[k], v)
 		}
+
+		return route
 	}
 
-	for _, route := range spec.Router.Routes {
+	for i, route := range routes {
 		if route.Default {
-			return route.DataAction, route.Template, true
+			return &routes[i]
 		}
 	}
 
-	return "", "", false
+	return nil
 }
 
 var (
@@ -103,7 +96,10 @@ func (c Index) IndexAction() {
 	c.AutoRender = false
 	start := time.Now().UnixNano()
 
-	if v := config.SysConfigList.FetchString("http_h_ac_allow_origin"); v != "" {
+	c.site = datax.SiteLookup(c.Request.Host)
+	c.Data["__site__"] = c.site
+
+	if v := c.sysConfig("http_h_ac_allow_origin"); v != "" {
 		c.Response.Out.Header().Set("Access-Control-Allow-Origin", v)
 	}
 
@@ -114,12 +110,40 @@ func (c Index) IndexAction() {
 	if reqpath == "" || reqpath == "." {
 		reqpath = "/"
 	}
+	reqpath, c.headless = c.headlessMode(reqpath)
 	if len(reqpath) > 0 && reqpath != "/" {
 		uris = strings.Split(strings.Trim(reqpath, "/"), "/")
 	}
 
+	if reqpath == "/robots.txt" {
+		c.robotsRender()
+		return
+	}
+
+	if c.robotsStaging() {
+		c.robotsTagSet("noindex, nofollow")
+	}
+
+	if v := c.Params.Get("hp_preview"); v != "" {
+		claim, err := datax.PreviewTokenVerify(v)
+		if err != nil {
+			c.RenderError(403, err.Error())
+			return
+		}
+		c.preview = claim
+		c.robotsTagSet("noindex, nofollow")
+	}
+
+	if strings.HasPrefix(reqpath, "/sitemap") && strings.HasSuffix(reqpath, ".xml") &&
+		c.sitemapRender(reqpath) {
+		return
+	}
+
 	if len(uris) < 1 {
-		if config.RouterBasepathDefault != "/" {
+		if c.site != nil && c.site.DefaultPath != "" {
+			reqpath = c.site.DefaultPath
+			uris = strings.Split(strings.Trim(reqpath, "/"), "/")
+		} else if config.RouterBasepathDefault != "/" {
 			reqpath = config.RouterBasepathDefault
 			uris = config.RouterBasepathDefaults
 		} else {
@@ -135,6 +159,9 @@ func (c Index) IndexAction() {
 
 	mod, ok := config.Modules[srvname]
 	if !ok {
+		if c.redirect(reqpath) {
+			return
+		}
 		srvname = srvnameDefault
 		mod, ok = config.Modules[srvname]
 		if !ok {
@@ -142,23 +169,41 @@ func (c Index) IndexAction() {
 		}
 	}
 
-	dataAction, template, mat := c.filter(uris[1:], mod)
-	if !mat {
+	if c.site != nil && !c.site.ModuleAllowed(srvname) {
+		c.notFound()
+		return
+	}
+
+	var (
+		route                = c.filter(uris[1:], mod)
+		dataAction, template string
+	)
+	if route != nil {
+		dataAction, template = route.DataAction, route.Template
+		if v := route.Robots.Directive(); v != "" {
+			c.robotsTagSet(v)
+		}
+	} else {
 		if uris[1] == "" {
 			template = "index.tpl"
+		} else if c.redirect(reqpath) {
+			return
 		} else {
 			template = "404.tpl"
 		}
 	}
 
 	lang := "en"
-	if lang, ok := c.Data["LANG"]; ok {
-		lang = strings.ToLower(lang.(string))
+	if v := c.Params.Get("lang"); v != "" {
+		lang = strings.ToLower(v)
+	} else if v, ok := c.Data["LANG"]; ok {
+		lang = strings.ToLower(v.(string))
 	}
-	c.Data["LANG"] = api.LangHit(config.Languages, lang)
+	langs := datax.SiteLanguages(c.site)
+	c.Data["LANG"] = api.LangHit(langs, lang)
 
-	if len(config.Languages) > 1 {
-		c.Data["frontend_langs"] = config.Languages
+	if len(langs) > 1 {
+		c.Data["frontend_langs"] = langs
 	}
 
 	// if session, err := c.Session.Instance(); err == nil {
@@ -170,11 +215,20 @@ func (c Index) IndexAction() {
 	c.Data["srvname"] = srvname
 	c.Data["modname"] = mod.Meta.Name
 	c.Data["sys_version_sign"] = config.SysVersionSign
+	c.Data["__html_site_url__"] = c.siteUrl()
 	if c.us.IsLogin() {
 		c.Data["s_user"] = c.us.UserName
 	}
 
-	drs := dataRenderOK
+	pageCacheKey, pageCacheTTL := c.pageCacheKey(route, reqpath)
+	if pageCacheKey != "" && c.pageCacheRender(pageCacheKey) {
+		return
+	}
+
+	var (
+		drs   = dataRenderOK
+		datas []api.ActionData
+	)
 
 	if dataAction != "" {
 
@@ -184,6 +238,9 @@ func (c Index) IndexAction() {
 				continue
 			}
 
+			datas = action.Datax
+			c.Data["__datax_action__"] = action.Name
+
 			for _, datax := range action.Datax {
 				drs = c.dataRender(srvname, action.Name, datax)
 				c.Data["__datax_table__"] = datax.Query.Table
@@ -196,12 +253,51 @@ func (c Index) IndexAction() {
 	switch drs {
 	case dataRenderOK:
 
-		// render_start := time.Now()
-		c.Render(mod.Meta.Name, template)
+		if c.httpCacheCheck(route, mod.Meta.Name, template) {
+			for _, fn := range c.hookPosts {
+				fn()
+			}
+			return
+		}
+
+		var pcw *pageCacheWriter
+		if pageCacheKey != "" {
+			pcw = &pageCacheWriter{ResponseWriter: c.Response.Out}
+			c.Response.Out = pcw
+		}
+
+		var pvw *previewWriter
+		if c.preview != nil {
+			pvw = &previewWriter{ResponseWriter: c.Response.Out}
+			c.Response.Out = pvw
+		}
+
+		feed := c.feedSpec(route)
+		if c.headless {
+			feed = nil
+			c.headlessRender(srvname, mod, route, datas)
+		} else if feed == nil || !c.feedRender(mod, dataAction, feed) {
+			feed = nil
+			// render_start := time.Now()
+			c.Render(mod.Meta.Name, template)
+			c.httpTemplateTagsSet(mod.Meta.Name, template)
+		}
+
+		if pvw != nil {
+			c.Response.Out = pvw.ResponseWriter
+			c.previewFlush(pvw)
+		}
+
+		if pcw != nil {
+			c.Response.Out = pcw.ResponseWriter
+			c.pageCachePut(pageCacheKey, pageCacheTTL, pcw)
+		}
 
 		// fmt.Println("render in-time", mod.Meta.Name, template, time.Since(render_start))
 
-		c.RenderString(fmt.Sprintf("<!-- rt-time/db+render : %d ms -->", (time.Now().UnixNano()-start)/1e6))
+		if feed == nil && !c.headless {
+			c.RenderString(fmt.Sprintf("<!-- rt-time/db+render : %d ms -->", (time.Now().UnixNano()-start)/1e6))
+		}
 
 		// fmt.Println("hookPosts", len(c.hookPosts))
 		for _, fn := range c.hookPosts {
@@ -209,10 +305,37 @@ func (c Index) IndexAction() {
 		}
 
 	case dataRenderNotFound:
+		if !c.redirect(reqpath) {
+			c.notFound()
+		}
+	}
+}
+
+func (c *Index) notFound() {
+	if c.headless {
+		c.headlessError(404, "Page Not Found")
+	} else {
 		c.RenderError(404, "Page Not Found")
 	}
 }
 
+func (c Index) redirect(reqpath string) bool {
+
+	to := datax.RedirectLookup(reqpath)
+	if to == "" {
+		return false
+	}
+
+	if c.Request.URL.RawQuery != "" {
+		to += "?" + c.Request.URL.RawQuery
+	}
+
+	c.Response.Out.Header().Set("Location", to)
+	c.Response.Out.WriteHeader(http.StatusMovedPermanently)
+
+	return true
+}
+
 func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 
 	mod, ok := config.Modules[srvname]
@@ -229,7 +352,11 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 		qry.Order(ad.Query.Order)
 	}
 
-	qry.Filter("status", 1)
+	if ad.Type == "node.entry" && c.preview != nil && c.preview.ModName == mod.Meta.Name {
+		qry.Filter("status.gt", 0)
+	} else {
+		qry.Filter("status", 1)
+	}
 
 	qry.Pager = ad.Pager
 
@@ -237,6 +364,9 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 
 	case "node.list":
 
+		sq := datax.NewNodeSearchQuery(c.Params.Get("qry_text"))
+		sq.Lang, _ = c.Data["LANG"].(string)
+
 		for _, modNode := range mod.NodeModels {
 
 			if ad.Query.Table != modNode.Meta.Name {
@@ -245,6 +375,8 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 
 			for _, term := range modNode.Terms {
 
+				sq.FacetSet(term.Meta.Name)
+
 				if termVal := c.Params.Get("term_" + term.Meta.Name); termVal != "" {
 
 					switch term.Type {
@@ -257,8 +389,12 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 								args = append(args, idx)
 							}
 							qry.Filter("term_"+term.Meta.Name+".in", args...)
+							sq.TermFilter(term.Meta.Name, idxs...)
 						} else {
 							qry.Filter("term_"+term.Meta.Name, termVal)
+							if tid, err := strconv.ParseUint(termVal, 10, 32); err == nil {
+								sq.TermFilter(term.Meta.Name, uint32(tid))
+							}
 						}
 
 						c.Data["term_"+term.Meta.Name] = termVal
@@ -266,6 +402,7 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 					case api.TermTag:
 						// TOPO
 						qry.Filter("term_"+term.Meta.Name+".like", "%"+termVal+"%")
+						sq.TermFilter(term.Meta.Name, datax.TermTagID(mod.Meta.Name, term.Meta.Name, termVal))
 						c.Data["term_"+term.Meta.Name] = termVal
 					}
 				}
@@ -274,6 +411,18 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			break
 		}
 
+		if t, err := time.ParseInLocation("2006-01-02", c.Params.Get("date_from"), time.Local); err == nil {
+			sq.CreatedMin = uint32(t.Unix())
+			qry.Filter("created.ge", sq.CreatedMin)
+			c.Data["date_from"] = c.Params.Get("date_from")
+		}
+
+		if t, err := time.ParseInLocation("2006-01-02", c.Params.Get("date_to"), time.Local); err == nil {
+			sq.CreatedMax = uint32(t.Unix()) + 86399
+			qry.Filter("created.le", sq.CreatedMax)
+			c.Data["date_to"] = c.Params.Get("date_to")
+		}
+
 		page := c.Params.Int64("page")
 		if page > 1 {
 			qry.Offset(ad.Query.Limit * (page - 1))
@@ -293,10 +442,14 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			}
 		}
 
+		if len(ls.Items) > 0 && ls.SearchLogID != "" {
+			ls.SearchLogID = datax.SearchLogPut(sq.Text, mod.Meta.Name, ad.Query.Table, ls.Meta.TotalResults)
+		}
+
 		if len(ls.Items) == 0 {
 
 			if c.Params.Get("qry_text") != "" {
-				ls = qry.NodeListSearch(c.Params.Get("qry_text"))
+				ls = qry.NodeListSearch(sq)
 				if ls.Error != nil {
 					ls = qry.NodeList([]string{}, []string{})
 				}
@@ -314,6 +467,7 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			}
 		}
 
+		c.validator.nodeList(mod.Meta.Name, ad.Query.Table, ad.Name, &ls)
 		c.Data[ad.Name] = ls
 
 		if qry.Pager {
@@ -352,6 +506,9 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 		if mod.Meta.Name == "core/gdoc" {
 			if ad.Query.Table == "page" {
 				nodeId = strings.ToLower(c.Request.UrlPathExtra)
+				if c.headless {
+					nodeId = strings.TrimSuffix(nodeId, headlessSuffix)
+				}
 			} else if ad.Query.Table == "doc" && api.NodeIdReg.MatchString(nodeId) {
 				nodeExt = "html"
 			}
@@ -389,7 +546,8 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 
 		var entry api.Node
 		qryhash := qry.Hash()
-		if ad.CacheTTL > 0 && (!c.us.IsLogin() || c.us.UserName != config.Config.AppInstance.Meta.User) {
+		if ad.CacheTTL > 0 && c.preview == nil &&
+			(!c.us.IsLogin() || c.us.UserName != config.Config.AppInstance.Meta.User) {
 			if rs := store.DataLocal.NewReader([]byte(qryhash)).Query(); rs.OK() {
 				rs.Decode(&entry)
 			}
@@ -397,7 +555,7 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 
 		if entry.ID == "" {
 			entry = qry.NodeEntry()
-			if ad.CacheTTL > 0 && entry.Title != "" {
+			if ad.CacheTTL > 0 && entry.Title != "" && c.preview == nil {
 				c.hookPosts = append(
 					c.hookPosts,
 					func() {
@@ -407,17 +565,18 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			}
 		}
 
-		if entry.ID == "" {
+		if entry.ID == "" ||
+			(entry.Status != 1 && !c.preview.Allowed(mod.Meta.Name, entry.ID)) {
 			return dataRenderNotFound
 		}
 
-		if nodeModel.Extensions.AccessCounter {
-
-			if ips := strings.Split(c.Request.RemoteAddr, ":"); len(ips) > 1 {
+		if v := c.Params.Get("search_log"); v != "" {
+			datax.SearchLogClick(v, mod.Meta.Name, entry.ID)
+		}
 
-				table := fmt.Sprintf("hpn_%s_%s", idhash.HashToHexString([]byte(mod.Meta.Name), 12), ad.Query.Table)
-				store.DataLocal.NewWriter([]byte("access_counter/"+table+"/"+ips[0]+"/"+entry.ID), "1").Commit()
-			}
+		if nodeModel.Extensions.AccessCounter && c.preview == nil {
+			table := fmt.Sprintf("hpn_%s_%s", idhash.HashToHexString([]byte(mod.Meta.Name), 12), ad.Query.Table)
+			c.accessCount(table + "/" + entry.ID)
 		}
 
 		if nodeModel.Extensions.NodeSubRefer != "" {
@@ -429,6 +588,9 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			c.Data["__html_head_title__"] = datax.StringSub(datax.TextHtml2Str(entry.Title), 0, 50)
 		}
 
+		c.seoHeadSet(mod, ad.Query.Table, &entry)
+
+		c.validator.node(mod.Meta.Name, ad.Query.Table, &entry)
 		c.Data[ad.Name] = entry
 
 	case "term.list":
@@ -448,6 +610,7 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			}
 		}
 
+		c.validator.termList(mod.Meta.Name, ad.Query.Table, ad.Name, &ls)
 		c.Data[ad.Name] = ls
 
 		if qry.Pager {
@@ -475,6 +638,7 @@ func (c *Index) dataRender(srvname, action_name string, ad api.ActionData) int {
 			}
 		}
 
+		c.validator.term(mod.Meta.Name, ad.Query.Table, &entry)
 		c.Data[ad.Name] = entry
 	}
 
@@ -0,0 +1,171 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+	"github.com/hooto/hpress/store"
+)
+
+var (
+	pageCacheQueryParams = []string{"page", "date_from", "date_to", "feed", "lang"}
+)
+
+type pageCacheWriter struct {
+	http.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *pageCacheWriter) Write(b []byte) (int, error) {
+	w.buf.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// pageCacheKey returns the output cache key of the request, or an empty key
+// if the page can not be cached. Only the query params which change the
+// page are part of the key.
+func (c *Index) pageCacheKey(route *api.Route, reqpath string) (string, int64) {
+
+	if route == nil || c.Request.Method != "GET" || c.us.IsLogin() || c.preview != nil {
+		return "", 0
+	}
+
+	if route.Cache != nil &&
+		(route.Cache.Scope == api.RouteCachePrivate || route.Cache.Scope == api.RouteCacheNoStore) {
+		return "", 0
+	}
+
+	ttl := datax.PageCacheTTL()
+	if ttl < 1 {
+		return "", 0
+	}
+
+	// the search results and clicks are logged by every request
+	query := c.Request.URL.Query()
+	if query.Get("search_log") != "" || query.Get("qry_text") != "" {
+		return "", 0
+	}
+
+	args := []string{}
+	for k, vs := range query {
+		allowed := strings.HasPrefix(k, "term_")
+		for _, v := range pageCacheQueryParams {
+			if k == v {
+				allowed = true
+				break
+			}
+		}
+		if allowed && len(vs) > 0 {
+			args = append(args, k+"="+vs[0])
+		}
+	}
+	sort.Strings(args)
+
+	lang, _ := c.Data["LANG"].(string)
+
+	mode := "html"
+	if c.headless {
+		mode = "json"
+	}
+
+	// the absolute urls of the page are built from the scheme and host
+	return datax.PageCacheKey(config.SysVersionSign,
+		c.hostUrl(), reqpath, lang, mode, strings.Join(args, "&")), ttl
+}
+
+// pageCacheRender writes the cached page of the key if it exists
+func (c *Index) pageCacheRender(key string) bool {
+
+	entry := datax.PageCacheEntry(key)
+	if entry == nil {
+		return false
+	}
+
+	for _, v := range entry.Counters {
+		c.accessCount(v)
+	}
+
+	hdr := c.Response.Out.Header()
+
+	if entry.ETag != "" {
+		hdr.Set("ETag", entry.ETag)
+		hdr.Set("Vary", "Accept")
+	}
+	if entry.LastModified != "" {
+		hdr.Set("Last-Modified", entry.LastModified)
+	}
+	if entry.CacheControl != "" {
+		hdr.Set("Cache-Control", entry.CacheControl)
+	}
+	if entry.RobotsTag != "" {
+		c.robotsTagSet(entry.RobotsTag)
+	}
+
+	if inm := c.Request.Header.Get("If-None-Match"); inm != "" && entry.ETag != "" &&
+		httpETagMatch(inm, entry.ETag) {
+		c.Response.Out.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	if entry.ContentType != "" {
+		hdr.Set("Content-Type", entry.ContentType)
+	}
+	hdr.Set("X-Page-Cache", "HIT")
+
+	c.Response.Out.Write([]byte(entry.Body))
+
+	return true
+}
+
+func (c *Index) pageCachePut(key string, ttl int64, w *pageCacheWriter) {
+
+	hdr := w.Header()
+
+	// the tags of the pagelets and menus are known after the page is rendered
+	for _, tag := range datax.PageCacheDataTags(c.Data) {
+		c.validator.tags.Set(tag)
+	}
+
+	datax.PageCachePut(key, &api.PageCacheEntry{
+		ContentType:  hdr.Get("Content-Type"),
+		ETag:         hdr.Get("ETag"),
+		LastModified: hdr.Get("Last-Modified"),
+		CacheControl: hdr.Get("Cache-Control"),
+		RobotsTag:    hdr.Get("X-Robots-Tag"),
+		Body:         w.buf.String(),
+		Tags:         c.validator.tags,
+		Counters:     c.counters,
+	}, ttl)
+}
+
+// accessCount counts an access of the client to the node of table/id, the
+// counters are merged into the nodes by the datax worker
+func (c *Index) accessCount(node string) {
+
+	c.counters = append(c.counters, node)
+
+	if ips := strings.Split(c.Request.RemoteAddr, ":"); len(ips) > 1 {
+		if n := strings.LastIndex(node, "/"); n > 0 {
+			store.DataLocal.NewWriter([]byte("access_counter/"+node[:n]+"/"+ips[0]+node[n:]), "1").Commit()
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+var (
+	previewBodyReg = regexp.MustCompile(`(?i)<body[^>]*>`)
+)
+
+const previewBanner = `<div id="hp-preview-banner" style="position:fixed;z-index:99999;left:0;right:0;bottom:0;` +
+	`padding:8px 16px;background:#c0392b;color:#fff;font:14px/1.5 sans-serif;text-align:center;">` +
+	`Preview of unpublished content, the link expires at %s</div>`
+
+// previewWriter holds the rendered page of a preview request, so that the
+// preview banner can be inserted after the body tag
+type previewWriter struct {
+	http.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *previewWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
+
+func (c *Index) previewFlush(w *previewWriter) {
+
+	var (
+		body = w.buf.Bytes()
+		hdr  = c.Response.Out.Header()
+	)
+
+	hdr.Set("Cache-Control", "no-store")
+
+	if loc := previewBodyReg.FindIndex(body); loc != nil {
+		banner := fmt.Sprintf(previewBanner,
+			time.Unix(c.preview.Expired, 0).Format("2006-01-02 15:04:05"))
+		c.Response.Out.Write(body[:loc[1]])
+		c.Response.Out.Write([]byte(banner))
+		c.Response.Out.Write(body[loc[1]:])
+	} else {
+		c.Response.Out.Write(body)
+	}
+}
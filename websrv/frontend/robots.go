@@ -0,0 +1,77 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"strings"
+)
+
+func (c *Index) robotsStaging() bool {
+	return c.sysConfig("frontend_robots_staging") == "1"
+}
+
+// robotsRender writes /robots.txt from the frontend_robots_txt policy,
+// the staging mode disallows all pages
+func (c *Index) robotsRender() {
+
+	var body string
+
+	if c.robotsStaging() {
+		body = "User-agent: *\nDisallow: /\n"
+	} else {
+
+		body = strings.TrimSpace(strings.Replace(
+			c.sysConfig("frontend_robots_txt"), "\r\n", "\n", -1))
+		if body != "" {
+			body += "\n"
+		}
+
+		if !strings.Contains(strings.ToLower(body), "sitemap:") {
+			body += "\nSitemap: " + c.siteUrl() + "/sitemap.xml\n"
+		}
+	}
+
+	c.Response.Out.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Response.Out.Write([]byte(body))
+}
+
+// robotsTagSet merges the directives into the X-Robots-Tag header
+func (c *Index) robotsTagSet(directive string) {
+
+	var (
+		hdr  = c.Response.Out.Header()
+		tags = []string{}
+	)
+
+	for _, v := range strings.Split(hdr.Get("X-Robots-Tag")+","+directive, ",") {
+		if v = strings.TrimSpace(v); v == "" {
+			continue
+		}
+		found := false
+		for _, v2 := range tags {
+			if v2 == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, v)
+		}
+	}
+
+	if len(tags) > 0 {
+		hdr.Set("X-Robots-Tag", strings.Join(tags, ", "))
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"strings"
+	"time"
+
+	"github.com/hooto/httpsrv"
+	"github.com/hooto/iam/iamapi"
+	"github.com/hooto/iam/iamclient"
+	"github.com/lessos/lessgo/x/webui"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+type Search struct {
+	*httpsrv.Controller
+	us iamapi.UserSession
+}
+
+func (c *Search) Init() int {
+	c.us, _ = iamclient.SessionInstance(c.Session)
+	return 0
+}
+
+var (
+	searchGlobalLimit int64 = 10
+)
+
+func (c Search) IndexAction() {
+
+	c.AutoRender = false
+
+	site := datax.SiteLookup(c.Request.Host)
+	c.Data["__site__"] = site
+
+	lang := "en"
+	if v, ok := c.Data["LANG"]; ok {
+		lang = strings.ToLower(v.(string))
+	}
+	c.Data["LANG"] = api.LangHit(datax.SiteLanguages(site), lang)
+
+	c.Data["baseuri"] = "/search"
+	c.Data["http_request_path"] = "/search"
+	c.Data["srvname"] = "core-general"
+	c.Data["modname"] = "core/general"
+	c.Data["sys_version_sign"] = config.SysVersionSign
+	if c.us.IsLogin() {
+		c.Data["s_user"] = c.us.UserName
+	}
+
+	if qryText := strings.TrimSpace(c.Params.Get("qry_text")); qryText != "" {
+
+		sq := datax.NewNodeSearchQuery(qryText)
+		sq.Site = site
+		sq.Lang, _ = c.Data["LANG"].(string)
+
+		if t, err := time.ParseInLocation("2006-01-02", c.Params.Get("date_from"), time.Local); err == nil {
+			sq.CreatedMin = uint32(t.Unix())
+			c.Data["date_from"] = c.Params.Get("date_from")
+		}
+
+		if t, err := time.ParseInLocation("2006-01-02", c.Params.Get("date_to"), time.Local); err == nil {
+			sq.CreatedMax = uint32(t.Unix()) + 86399
+			c.Data["date_to"] = c.Params.Get("date_to")
+		}
+
+		page := c.Params.Int64("page")
+		if page < 1 {
+			page = 1
+		}
+
+		ls := datax.NodeGlobalSearch(sq, searchGlobalLimit*(page-1), searchGlobalLimit)
+
+		c.Data["qry_text"] = qryText
+		c.Data["list"] = ls
+
+		if ls.Error == nil {
+			pager := webui.NewPager(uint64(page),
+				ls.Meta.TotalResults,
+				ls.Meta.ItemsPerList,
+				10)
+			pager.CurrentPageNumber = uint64(page)
+			c.Data["list_pager"] = pager
+		}
+	}
+
+	c.Render("core/general", "search.tpl")
+}
@@ -0,0 +1,96 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"strings"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/datax"
+)
+
+// seoHeadSet exposes the head metadata of the node page to templates as
+// __html_head_seo__, it replaces the global keywords/description meta
+func (c *Index) seoHeadSet(mod *api.Spec, table string, entry *api.Node) {
+
+	lang, _ := c.Data["LANG"].(string)
+
+	var (
+		seo  = datax.NodeSeoMeta(mod, table, entry, lang)
+		host = c.hostUrl()
+		buf  strings.Builder
+	)
+
+	if seo.Canonical == "" {
+		seo.Canonical = host + c.Request.URL.Path
+	} else {
+		seo.Canonical = seoUrl(c.siteUrl(), seo.Canonical)
+	}
+
+	if seo.Image != "" {
+		seo.Image = seoUrl(host, seo.Image)
+	}
+
+	meta := func(attr, name, content string) {
+		if content != "" {
+			fmt.Fprintf(&buf, "<meta %s=\"%s\" content=\"%s\">\n",
+				attr, name, html.EscapeString(content))
+		}
+	}
+
+	if kw := c.sysConfig("frontend_html_head_meta_keywords"); kw != "" {
+		meta("name", "keywords", kw)
+	}
+	meta("name", "description", seo.Description)
+
+	if v := api.RobotsDirective(seo.NoIndex, seo.NoFollow); v != "" {
+		meta("name", "robots", v)
+		c.robotsTagSet(v)
+	}
+
+	fmt.Fprintf(&buf, "<link rel=\"canonical\" href=\"%s\">\n", html.EscapeString(seo.Canonical))
+
+	meta("property", "og:type", "article")
+	meta("property", "og:site_name", c.sysConfig("frontend_header_site_name"))
+	meta("property", "og:title", seo.Title)
+	meta("property", "og:description", seo.Description)
+	meta("property", "og:url", seo.Canonical)
+	meta("property", "og:image", seo.Image)
+
+	if seo.Image != "" {
+		meta("name", "twitter:card", "summary_large_image")
+	} else {
+		meta("name", "twitter:card", "summary")
+	}
+	meta("name", "twitter:title", seo.Title)
+	meta("name", "twitter:description", seo.Description)
+	meta("name", "twitter:image", seo.Image)
+
+	if entry.ExtSeo != nil && entry.ExtSeo.Title != "" {
+		c.Data["__html_head_title__"] = seo.Title
+	}
+
+	c.Data["__html_head_seo__"] = template.HTML(buf.String())
+}
+
+func seoUrl(prefix, path string) string {
+	if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+	return prefix + "/" + strings.TrimLeft(path, "/")
+}
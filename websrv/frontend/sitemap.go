@@ -0,0 +1,164 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+var (
+	sitemapFileReg = regexp.MustCompile(`^/sitemap-([0-9a-z\-_]+)-([0-9]+)\.xml$`)
+)
+
+type sitemapIndex struct {
+	XMLName  xml.Name           `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapIndexItem `xml:"sitemap"`
+}
+
+type sitemapIndexItem struct {
+	Loc     string `xml:"loc"`
+	Lastmod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapUrlset struct {
+	XMLName xml.Name         `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	XhtmlNS string           `xml:"xmlns:xhtml,attr,omitempty"`
+	Urls    []sitemapUrlItem `xml:"url"`
+}
+
+type sitemapUrlItem struct {
+	Loc     string             `xml:"loc"`
+	Lastmod string             `xml:"lastmod,omitempty"`
+	Links   []sitemapXhtmlLink `xml:"xhtml:link"`
+}
+
+type sitemapXhtmlLink struct {
+	Rel      string `xml:"rel,attr"`
+	HrefLang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+func sitemapLastmod(tn uint32) string {
+	if tn < 1 {
+		return ""
+	}
+	return time.Unix(int64(tn), 0).UTC().Format(time.RFC3339)
+}
+
+// sitemapRender writes /sitemap.xml, the index of the module sitemaps, or
+// a /sitemap-<srvname>-<n>.xml file of a module
+func (c *Index) sitemapRender(reqpath string) bool {
+
+	var (
+		site = c.siteUrl()
+		doc  interface{}
+	)
+
+	if reqpath == "/sitemap.xml" {
+
+		idx := sitemapIndex{}
+
+		for _, mod := range datax.SitemapModules() {
+			if c.site != nil && !c.site.ModuleAllowed(mod.SrvName) {
+				continue
+			}
+			for i, file := range datax.SitemapFiles(mod) {
+				idx.Sitemaps = append(idx.Sitemaps, sitemapIndexItem{
+					Loc:     fmt.Sprintf("%s/sitemap-%s-%d.xml", site, mod.SrvName, i+1),
+					Lastmod: sitemapLastmod(file.Updated),
+				})
+			}
+		}
+
+		doc = idx
+
+	} else {
+
+		mat := sitemapFileReg.FindStringSubmatch(reqpath)
+		if len(mat) != 3 {
+			return false
+		}
+
+		mod, ok := config.Modules[mat[1]]
+		if !ok || mod.Status != 1 ||
+			(c.site != nil && !c.site.ModuleAllowed(mod.SrvName)) {
+			return false
+		}
+
+		langs := datax.SiteLanguages(c.site)
+
+		n, _ := strconv.Atoi(mat[2])
+		files := datax.SitemapFiles(mod)
+		if n < 1 || n > len(files) {
+			return false
+		}
+
+		set := sitemapUrlset{}
+		if len(langs) > 1 {
+			set.XhtmlNS = "http://www.w3.org/1999/xhtml"
+		}
+
+		for _, u := range files[n-1].Urls {
+
+			item := sitemapUrlItem{
+				Loc:     site + u.Path,
+				Lastmod: sitemapLastmod(u.Updated),
+			}
+
+			if len(langs) > 1 {
+				sep := "?"
+				if strings.Contains(item.Loc, "?") {
+					sep = "&"
+				}
+				for _, lang := range langs {
+					item.Links = append(item.Links, sitemapXhtmlLink{
+						Rel:      "alternate",
+						HrefLang: lang.Id,
+						Href:     item.Loc + sep + "lang=" + lang.Id,
+					})
+				}
+				item.Links = append(item.Links, sitemapXhtmlLink{
+					Rel:      "alternate",
+					HrefLang: "x-default",
+					Href:     item.Loc,
+				})
+			}
+
+			set.Urls = append(set.Urls, item)
+		}
+
+		doc = set
+	}
+
+	bs, err := xml.Marshal(doc)
+	if err != nil {
+		c.RenderError(500, err.Error())
+		return true
+	}
+
+	c.Response.Out.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.Response.Out.Write([]byte(xml.Header))
+	c.Response.Out.Write(bs)
+
+	return true
+}
@@ -0,0 +1,93 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package frontend
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestSitemapXml(t *testing.T) {
+
+	set := sitemapUrlset{
+		XhtmlNS: "http://www.w3.org/1999/xhtml",
+		Urls: []sitemapUrlItem{{
+			Loc:     "http://example.com/blog?a=1&b=2",
+			Lastmod: sitemapLastmod(1546398245),
+			Links: []sitemapXhtmlLink{
+				{Rel: "alternate", HrefLang: "en", Href: "http://example.com/en/blog"},
+			},
+		}, {
+			Loc: "http://example.com/about",
+		}},
+	}
+
+	bs, err := xml.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed on Marshal, err %s", err.Error())
+	}
+	s := string(bs)
+
+	for _, v := range []string{
+		`<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">`,
+		`<loc>http://example.com/blog?a=1&amp;b=2</loc>`,
+		`<lastmod>2019-01-02T03:04:05Z</lastmod>`,
+		`<xhtml:link rel="alternate" hreflang="en" href="http://example.com/en/blog">`,
+		`<url><loc>http://example.com/about</loc></url>`,
+	} {
+		if !strings.Contains(s, v) {
+			t.Fatalf("Failed on Urlset, expect %s, got %s", v, s)
+		}
+	}
+
+	bs, err = xml.Marshal(sitemapIndex{
+		Sitemaps: []sitemapIndexItem{{Loc: "http://example.com/sitemap-blog-1.xml"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed on Marshal, err %s", err.Error())
+	}
+	if s, v := string(bs), `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9"><sitemap><loc>http://example.com/sitemap-blog-1.xml</loc></sitemap></sitemapindex>`; s != v {
+		t.Fatalf("Failed on Index, expect %s, got %s", v, s)
+	}
+
+	if s := sitemapLastmod(0); s != "" {
+		t.Fatalf("Failed on Lastmod, expect empty, got %s", s)
+	}
+}
+
+func TestSitemapFileReg(t *testing.T) {
+
+	for _, v := range []struct {
+		path    string
+		srvname string
+		num     string
+	}{
+		{"/sitemap-blog-1.xml", "blog", "1"},
+		{"/sitemap-gdoc-v2-12.xml", "gdoc-v2", "12"},
+		{"/sitemap.xml", "", ""},
+		{"/sitemap-blog.xml", "", ""},
+		{"/sitemap-Blog-1.xml", "", ""},
+	} {
+		var srvname, num string
+		if ms := sitemapFileReg.FindStringSubmatch(v.path); len(ms) == 3 {
+			srvname, num = ms[1], ms[2]
+		}
+		if srvname != v.srvname || num != v.num {
+			t.Fatalf("Failed on FileReg %s, expect %s %s, got %s %s",
+				v.path, v.srvname, v.num, srvname, num)
+		}
+	}
+}
@@ -37,6 +37,17 @@ func NewModule() httpsrv.Module {
 	//
 	module.ControllerRegister(new(Text))
 
+	//
+	module.ControllerRegister(new(Search))
+	module.ControllerRegister(new(SearchIndex))
+	module.ControllerRegister(new(SearchLog))
+	module.ControllerRegister(new(SearchDict))
+
+	//
+	module.ControllerRegister(new(Redirect))
+	module.ControllerRegister(new(Site))
+	module.ControllerRegister(new(Menu))
+
 	//
 	module.ControllerRegister(new(Sys))
 
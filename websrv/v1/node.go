@@ -20,6 +20,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/hooto/hlog4g/hlog"
 	"github.com/hooto/httpsrv"
 	"github.com/hooto/iam/iamapi"
 	"github.com/hooto/iam/iamclient"
@@ -178,6 +179,7 @@ func (c Node) SetAction() {
 		table_prefix = fmt.Sprintf("hpn_%s_", idhash.HashToHexString([]byte(c.Params.Get("modname")), 12))
 		table        = table_prefix + c.Params.Get("modelid")
 		node_refer   = ""
+		prev_perma   = ""
 	)
 
 	//
@@ -189,6 +191,13 @@ func (c Node) SetAction() {
 		}
 	}
 
+	if model.Extensions.Seo && rsp.ExtSeo != nil {
+		if err := rsp.ExtSeo.Valid(); err != nil {
+			rsp.Error = types.NewErrorMeta("400", err.Error())
+			return
+		}
+	}
+
 	if model.Extensions.NodeRefer != "" {
 		if !api.NodeExtNodeReferReg.MatchString(rsp.ExtNodeRefer) {
 			rsp.Error = types.NewErrorMeta("400", "Invalid Node Refer ID")
@@ -237,7 +246,8 @@ func (c Node) SetAction() {
 		}
 
 		if model.Extensions.Permalink != "" {
-			set["ext_permalink_name"] = rs[0].Field("ext_permalink_name").String()
+			prev_perma = rs[0].Field("ext_permalink_name").String()
+			set["ext_permalink_name"] = prev_perma
 		}
 
 		if model.Extensions.NodeRefer != "" {
@@ -530,6 +540,15 @@ func (c Node) SetAction() {
 		}
 	}
 
+	if model.Extensions.Seo {
+		if rsp.ExtSeo.IsEmpty() {
+			set["ext_seo"] = ""
+		} else {
+			seo_js, _ := json.Encode(rsp.ExtSeo, "")
+			set["ext_seo"] = string(seo_js)
+		}
+	}
+
 	if model.Extensions.NodeRefer != "" {
 
 		if prev, ok := set["ext_node_refer"]; !ok || prev != rsp.ExtNodeRefer {
@@ -575,6 +594,16 @@ func (c Node) SetAction() {
 			}
 			return
 		}
+
+		datax.NodeChanged(c.Params.Get("modname"), model.Meta.Name, rsp.ID)
+
+		if perma, ok := set["ext_permalink_name"]; ok && prev_perma != "" && perma.(string) != prev_perma {
+			rsp.ExtPermalinkName = perma.(string)
+			rsp.ExtNodeRefer = node_refer
+			if err := datax.NodeRedirectSet(c.Params.Get("modname"), model.Meta.Name, &rsp, prev_perma); err != nil {
+				hlog.Printf("warn", "node redirect set %s, err %s", rsp.ID, err.Error())
+			}
+		}
 	}
 
 	rsp.Kind = "Node"
@@ -639,7 +668,75 @@ func (c Node) DelAction() {
 			}
 			return
 		}
+
+		datax.NodeChanged(c.Params.Get("modname"), c.Params.Get("modelid"), id)
 	}
 
 	rsp.Kind = "Node"
 }
+
+func (c Node) PreviewAction() {
+
+	rsp := api.NodePreview{}
+	defer c.RenderJson(&rsp)
+
+	if !iamclient.SessionAccessAllowed(c.Session, "editor.read", config.Config.InstanceID) {
+		rsp.Error = &types.ErrorMeta{iamapi.ErrCodeAccessDenied, "Access Denied"}
+		return
+	}
+
+	spec := config.SpecGet(c.Params.Get("modname"))
+	if spec == nil {
+		rsp.Error = types.NewErrorMeta("404", "Spec Not Found")
+		return
+	}
+
+	model := spec.NodeModelGet(c.Params.Get("modelid"))
+	if model == nil {
+		rsp.Error = types.NewErrorMeta("404", "Model Not Found")
+		return
+	}
+
+	dq := datax.NewQuery(spec.Meta.Name, model.Meta.Name)
+	dq.Filter("status.gt", 0)
+	dq.Filter("id", c.Params.Get("id"))
+
+	node := dq.NodeEntry()
+	if node.ID == "" {
+		rsp.Error = types.NewErrorMeta("404", "Node Not Found")
+		return
+	}
+
+	var (
+		ids  = []string{node.ID}
+		link = datax.NodePermalink(spec, model.Meta.Name, &node)
+	)
+
+	if link == "" && model.Extensions.NodeRefer != "" && node.ExtNodeRefer != "" {
+
+		rq := datax.NewQuery(spec.Meta.Name, model.Extensions.NodeRefer)
+		rq.Filter("status.gt", 0)
+		rq.Filter("id", node.ExtNodeRefer)
+
+		if refer := rq.NodeEntry(); refer.ID != "" {
+			ids = append(ids, refer.ID)
+			link = datax.NodeReferPermalink(spec, model.Meta.Name, &node,
+				model.Extensions.NodeRefer, &refer)
+		}
+	}
+
+	if link == "" {
+		rsp.Error = types.NewErrorMeta("400", "No Route Found for the Node")
+		return
+	}
+
+	token, expired, err := datax.PreviewTokenNew(spec.Meta.Name, ids, c.Params.Int64("ttl"))
+	if err != nil {
+		rsp.Error = types.NewErrorMeta("500", err.Error())
+		return
+	}
+
+	rsp.Url = config.HttpSrvBasePath(link) + "?hp_preview=" + token
+	rsp.Expired = expired
+	rsp.Kind = "NodePreview"
+}
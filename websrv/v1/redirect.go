@@ -0,0 +1,85 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"github.com/hooto/httpsrv"
+	"github.com/hooto/iam/iamapi"
+	"github.com/hooto/iam/iamclient"
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
+)
+
+type Redirect struct {
+	*httpsrv.Controller
+	us iamapi.UserSession
+}
+
+func (c *Redirect) Init() int {
+
+	//
+	c.us, _ = iamclient.SessionInstance(c.Session)
+
+	if !c.us.IsLogin() {
+		c.Response.Out.WriteHeader(401)
+		c.RenderJson(types.NewTypeErrorMeta(iamapi.ErrCodeUnauthorized, "Unauthorized"))
+		return 1
+	}
+
+	if !iamclient.SessionAccessAllowed(c.Session, "sys.admin", config.Config.InstanceID) {
+		c.RenderJson(types.NewTypeErrorMeta(iamapi.ErrCodeAccessDenied, "Access Denied"))
+		return 1
+	}
+
+	return 0
+}
+
+func (c Redirect) ListAction() {
+	ls := datax.RedirectList()
+	c.RenderJson(&ls)
+}
+
+func (c Redirect) SetAction() {
+
+	rsp := api.Redirect{}
+	defer c.RenderJson(&rsp)
+
+	if err := c.Request.JsonDecode(&rsp); err != nil {
+		rsp.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Bad Request: "+err.Error())
+		return
+	}
+
+	rsp.Auto = false
+
+	if err := datax.RedirectSet(&rsp); err != nil {
+		rsp.Error = types.NewErrorMeta(api.ErrCodeBadArgument, err.Error())
+	}
+}
+
+func (c Redirect) DelAction() {
+
+	rsp := types.TypeMeta{}
+	defer c.RenderJson(&rsp)
+
+	if err := datax.RedirectDel(c.Params.Get("from")); err != nil {
+		rsp.Error = types.NewErrorMeta(api.ErrCodeBadArgument, err.Error())
+		return
+	}
+
+	rsp.Kind = "Redirect"
+}
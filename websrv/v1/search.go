@@ -0,0 +1,76 @@
+// Copyright 2015 Eryx <evorui аt gmаil dοt cοm>, All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1
+
+import (
+	"strings"
+
+	"github.com/hooto/httpsrv"
+	"github.com/lessos/lessgo/types"
+
+	"github.com/hooto/hpress/api"
+	"github.com/hooto/hpress/datax"
+)
+
+type Search struct {
+	*httpsrv.Controller
+}
+
+var (
+	search_list_limit int64 = 10
+)
+
+func (c Search) QueryAction() {
+
+	ls := api.NodeSearchResultList{}
+
+	defer c.RenderJson(&ls)
+
+	qryText := strings.TrimSpace(c.Params.Get("qry_text"))
+	if qryText == "" {
+		ls.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Query Text Not Found")
+		return
+	}
+
+	limit := c.Params.Int64("limit")
+	if limit < 1 || limit > 50 {
+		limit = search_list_limit
+	}
+
+	page := c.Params.Int64("page")
+	if page < 1 {
+		page = 1
+	}
+
+	sq := datax.NewNodeSearchQuery(qryText)
+	sq.Lang = c.Params.Get("lang")
+
+	ls = datax.NodeGlobalSearch(sq, limit*(page-1), limit)
+}
+
+func (c Search) SuggestAction() {
+
+	ls := api.NodeSearchSuggestList{}
+
+	defer c.RenderJson(&ls)
+
+	qryText := strings.TrimSpace(c.Params.Get("qry_text"))
+	if qryText == "" {
+		ls.Error = types.NewErrorMeta(api.ErrCodeBadArgument, "Query Text Not Found")
+		return
+	}
+
+	ls = datax.NodeSearchSuggest(qryText, c.Params.Get("modname"), c.Params.Get("modelid"))
+}
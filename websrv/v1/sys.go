@@ -29,6 +29,7 @@ import (
 
 	"github.com/hooto/hpress/api"
 	"github.com/hooto/hpress/config"
+	"github.com/hooto/hpress/datax"
 	"github.com/hooto/hpress/status"
 	"github.com/hooto/hpress/store"
 )
@@ -158,6 +159,8 @@ func (c Sys) ConfigSetAction() {
 		config.SysConfigList.Insert(entry)
 	}
 
+	datax.PageCachePurge(datax.PageCacheTagPagelets())
+
 	ls.Kind = "SysConfigList"
 }
 
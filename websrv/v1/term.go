@@ -312,6 +312,8 @@ func (c Term) SetAction() {
 			}
 			return
 		}
+
+		datax.TermChanged(c.Params.Get("modname"), model.Meta.Name, rsp.ID)
 	}
 
 	rsp.Model = model
@@ -674,6 +674,10 @@ hpNode.Set = function(modname, modelid, nodeid, referid) {
                 data.ext_text_search = false;
             }
 
+            if (!data.ext_seo) {
+                data.ext_seo = {};
+            }
+
             $(alertid).hide();
 
 
@@ -997,6 +1001,22 @@ hpNode.Set = function(modname, modelid, nodeid, referid) {
                         });
                     }
 
+                    if (data.model.extensions.seo) {
+                        l4iTemplate.Render({
+                            dstid: field_layout_target,
+                            tplid: "hpm-nodeset-tplext_seo",
+                            append: true,
+                            data: {
+                                title: data.ext_seo.title || "",
+                                description: data.ext_seo.description || "",
+                                canonical: data.ext_seo.canonical || "",
+                                image: data.ext_seo.image || "",
+                                noindex: data.ext_seo.noindex ? true : false,
+                                nofollow: data.ext_seo.nofollow ? true : false,
+                            },
+                        });
+                    }
+
                     l4iTemplate.Render({
                         dstid: field_layout_target,
                         tplid: "hpm-nodeset-tplstatus",
@@ -1189,6 +1209,17 @@ hpNode.SetCommit = function(options) {
         req.ext_comment_perentry = true;
     }
 
+    if (hpNode.setCurrent.model.extensions.seo) {
+        req.ext_seo = {
+            title: form.find("input[name=ext_seo_title]").val(),
+            description: form.find("textarea[name=ext_seo_description]").val(),
+            canonical: form.find("input[name=ext_seo_canonical]").val(),
+            image: form.find("input[name=ext_seo_image]").val(),
+            noindex: (form.find("select[name=ext_seo_noindex]").val() == "true"),
+            nofollow: (form.find("select[name=ext_seo_nofollow]").val() == "true"),
+        };
+    }
+
     // return console.log(req);
     for (var i in hpNode.setCurrent.model.fields) {
 
@@ -1389,6 +1420,22 @@ hpNode.DelCommit = function(modname, modelid, id) {
     });
 }
 
+hpNode.Preview = function(modname, modelid, id) {
+    var uri = "modname=" + modname + "&modelid=" + modelid + "&id=" + id;
+
+    hpMgr.ApiCmd("node/preview?" + uri, {
+        callback: function(err, data) {
+
+            if (!data || data.kind != "NodePreview") {
+                var msg = (data && data.error) ? data.error.message : "Network Connection Exception";
+                return l4i.InnerAlert("#hpm-node-alert", 'alert-danger', msg);
+            }
+
+            window.open(data.url, "_blank");
+        }
+    });
+}
+
 hpNode.DelBatch = function(modname, modelid, ids, cb) {
     var uri = "modname=" + modname + "&modelid=" + modelid + "&id=" + ids.join(",");
 
@@ -55,6 +55,7 @@ var hpSpec = {
             comment_perentry: false,
             node_refer: "",
             text_search: false,
+            seo: false,
         },
     },
 
@@ -176,6 +177,20 @@ var hpSpec = {
         name: "OFF",
     }],
 
+    structured_data_def: [{
+        type: "",
+        name: "OFF",
+    }, {
+        type: "Article",
+        name: "Article",
+    }, {
+        type: "BlogPosting",
+        name: "BlogPosting",
+    }, {
+        type: "TechArticle",
+        name: "TechArticle",
+    }],
+
     permalink_def: [{
         type: "",
         name: "OFF",
@@ -817,12 +832,25 @@ hpSpec.NodeSet = function(modname, modelid) {
             if (!data.extensions.text_search) {
                 data.extensions.text_search = false;
             }
+            if (!data.extensions.seo) {
+                data.extensions.seo = false;
+            }
+            if (!data.structured_data) {
+                data.structured_data = {};
+            }
+            var sd_keys = ["type", "headline", "description", "body", "keywords", "section"];
+            for (var i in sd_keys) {
+                if (!data.structured_data[sd_keys[i]]) {
+                    data.structured_data[sd_keys[i]] = "";
+                }
+            }
 
 
             data._field_idx_typedef = hpSpec.field_idx_typedef;
             data._field_typedef = hpSpec.field_typedef;
             data._general_onoff = hpSpec.general_onoff;
             data._permalink_def = hpSpec.permalink_def;
+            data._structured_data_def = hpSpec.structured_data_def;
 
             //
             if (!data.terms) {
@@ -941,6 +969,7 @@ hpSpec.NodeSetCommit = function() {
             comment_perentry: false,
             node_refer: "",
             text_search: false,
+            seo: false,
         },
     };
 
@@ -952,6 +981,22 @@ hpSpec.NodeSetCommit = function() {
         req.extensions.text_search = true;
     }
 
+    if (form.find("select[name=ext_seo]").val() == "true") {
+        req.extensions.seo = true;
+    }
+
+    var sd_type = form.find("select[name=sd_type]").val();
+    if (sd_type && sd_type != "") {
+        req.structured_data = {
+            type: sd_type,
+            headline: form.find("input[name=sd_headline]").val(),
+            description: form.find("input[name=sd_description]").val(),
+            body: form.find("input[name=sd_body]").val(),
+            keywords: form.find("input[name=sd_keywords]").val(),
+            section: form.find("input[name=sd_section]").val(),
+        };
+    }
+
     if (form.find("select[name=ext_comment_enable]").val() == "true") {
         req.extensions.comment_enable = true;
     }
@@ -1857,11 +1902,35 @@ hpSpec.RouteSetCommit = function() {
         template: form.find("input[name=template]").val(),
         modname: form.find("input[name=modname]").val(),
         params: {},
+        priority: parseInt(form.find("input[name=priority]").val()) || 0,
         default: false,
     };
     if (form.find("select[name=default]").val() == "1") {
         req.default = true;
     }
+    var cache_scope = form.find("select[name=cache_scope]").val(),
+        cache_max_age = parseInt(form.find("input[name=cache_max_age]").val()) || 0;
+    if (cache_scope || cache_max_age > 0) {
+        req.cache = {
+            scope: cache_scope,
+            maxAge: cache_max_age,
+        };
+    }
+    var feed_format = form.find("select[name=feed_format]").val();
+    if (feed_format) {
+        req.feed = {
+            format: feed_format,
+            content: form.find("select[name=feed_content]").val(),
+        };
+    }
+    var robots_noindex = (form.find("select[name=robots_noindex]").val() == "1"),
+        robots_nofollow = (form.find("select[name=robots_nofollow]").val() == "1");
+    if (robots_noindex || robots_nofollow) {
+        req.robots = {
+            noindex: robots_noindex,
+            nofollow: robots_nofollow,
+        };
+    }
 
     try {
 
@@ -34,6 +34,7 @@ hpSys.Init = function() {
     l4i.UrlEventRegister("sys/status", hpSys.Status, "hpm-sys-nav");
     l4i.UrlEventRegister("sys/iam-status", hpSys.IamStatus, "hpm-sys-nav");
     l4i.UrlEventRegister("sys/config", hpSys.Config, "hpm-sys-nav");
+    l4i.UrlEventRegister("sys/site", hpSys.Site, "hpm-sys-nav");
 }
 
 hpSys.Index = function() {
@@ -129,6 +130,172 @@ hpSys.ConfigSetCommit = function() {
     });
 }
 
+hpSys.Site = function() {
+    seajs.use(["ep"], function(EventProxy) {
+
+        var ep = EventProxy.create('tpl', 'data', function(tpl, data) {
+
+            if (!data) {
+                return;
+            }
+
+            if (!data.items) {
+                data.items = [];
+            }
+            for (var i in data.items) {
+                if (!data.items[i].title) {
+                    data.items[i].title = "";
+                }
+                if (!data.items[i].hosts) {
+                    data.items[i].hosts = [];
+                }
+                if (!data.items[i].default_path) {
+                    data.items[i].default_path = "";
+                }
+                if (!data.items[i].updated) {
+                    data.items[i].updated = 0;
+                }
+            }
+
+            l4iTemplate.Render({
+                dstid: "work-content",
+                tplsrc: tpl,
+                data: data,
+            });
+        });
+
+        ep.fail(function(err) {
+            alert("Error: Please try again later");
+        });
+
+        hpMgr.ApiCmd("site/list", {
+            callback: ep.done('data'),
+        });
+
+        hpMgr.TplCmd("sys/site", {
+            callback: ep.done('tpl'),
+        });
+    });
+}
+
+hpSys.SiteEdit = function(name) {
+
+    var form = $("#hpm-sys-siteset"),
+        alertid = "#hpm-sys-siteset-alert";
+
+    hpMgr.ApiCmd("site/entry?name=" + name, {
+        callback: function(err, data) {
+
+            if (!data || data.kind != "Site") {
+                if (data && data.error) {
+                    return l4i.InnerAlert(alertid, 'alert-danger', data.error.message);
+                }
+                return l4i.InnerAlert(alertid, 'alert-danger', "Network Connection Exception");
+            }
+
+            var configs = [];
+            for (var i in data.configs) {
+                configs.push(data.configs[i].key + " = " + data.configs[i].value);
+            }
+
+            form.find("input[name=name]").val(data.name);
+            form.find("input[name=title]").val(data.title || "");
+            form.find("input[name=hosts]").val((data.hosts || []).join(", "));
+            form.find("input[name=default_path]").val(data.default_path || "");
+            form.find("input[name=modules]").val((data.modules || []).join(", "));
+            form.find("input[name=languages]").val(data.languages || "");
+            form.find("textarea[name=theme_config]").val(data.theme_config || "");
+            form.find("textarea[name=configs]").val(configs.join("\n"));
+        },
+    });
+}
+
+hpSys.SiteSetCommit = function() {
+
+    var form = $("#hpm-sys-siteset"),
+        alertid = "#hpm-sys-siteset-alert";
+
+    var listSplit = function(v) {
+        var ls = [];
+        var ar = v.split(",");
+        for (var i in ar) {
+            var s = ar[i].trim();
+            if (s.length > 0) {
+                ls.push(s);
+            }
+        }
+        return ls;
+    }
+
+    var req = {
+        name: form.find("input[name=name]").val().trim(),
+        title: form.find("input[name=title]").val(),
+        hosts: listSplit(form.find("input[name=hosts]").val()),
+        default_path: form.find("input[name=default_path]").val().trim(),
+        modules: listSplit(form.find("input[name=modules]").val()),
+        languages: form.find("input[name=languages]").val().trim(),
+        theme_config: form.find("textarea[name=theme_config]").val(),
+        configs: [],
+    };
+
+    var lines = form.find("textarea[name=configs]").val().split("\n");
+    for (var i in lines) {
+        var n = lines[i].indexOf("=");
+        if (n < 1) {
+            continue;
+        }
+        req.configs.push({
+            key: lines[i].substr(0, n).trim(),
+            value: lines[i].substr(n + 1).trim(),
+        });
+    }
+
+    hpMgr.ApiCmd("site/set", {
+        method: "PUT",
+        data: JSON.stringify(req),
+        callback: function(err, data) {
+
+            if (!data || !data.kind || data.kind != "Site") {
+
+                if (data && data.error) {
+                    return l4i.InnerAlert(alertid, 'alert-danger', data.error.message);
+                }
+
+                return l4i.InnerAlert(alertid, 'alert-danger', "Network Connection Exception");
+            }
+
+            l4i.InnerAlert(alertid, 'alert-success', "Successful updated");
+
+            window.setTimeout(function() {
+                hpSys.Site();
+            }, 1000);
+        },
+    });
+}
+
+hpSys.SiteDel = function(name) {
+
+    if (!confirm("Delete the site " + name + "?")) {
+        return;
+    }
+
+    var alertid = "#hpm-sys-site-alert";
+
+    hpMgr.ApiCmd("site/del?name=" + name, {
+        callback: function(err, data) {
+
+            if (!data || data.kind != "Site") {
+                if (data && data.error) {
+                    return l4i.InnerAlert(alertid, 'alert-danger', data.error.message);
+                }
+                return l4i.InnerAlert(alertid, 'alert-danger', "Network Connection Exception");
+            }
+
+            hpSys.Site();
+        },
+    });
+}
+
 
 hpSys.Status = function() {
     seajs.use(["ep"], function(EventProxy) {
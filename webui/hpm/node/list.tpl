@@ -73,6 +73,7 @@
       <td align="right">
         <button class="pure-button button-xsmall" onclick="hpNode.Del('{[=it.modname]}', '{[=it.modelid]}', '{[=v.id]}')">Delete</button>
         <button class="pure-button button-xsmall" onclick="hpNode.Set('{[=it.modname]}', '{[=it.modelid]}', '{[=v.id]}')">Edit</button>
+        <button class="pure-button button-xsmall" onclick="hpNode.Preview('{[=it.modname]}', '{[=it.modelid]}', '{[=v.id]}')">Preview</button>
       </td>
     </tr>
   {[~]}
@@ -158,6 +158,52 @@
 </script>
 
 
+<script id="hpm-nodeset-tplext_seo" type="text/html">
+  <div class="hpm-nodeset-tplx">
+    <label>SEO Title</label>
+    <div>
+      <input type="text" name="ext_seo_title" class="l4i-form-control" value="{[=it.title]}">
+    </div>
+  </div>
+  <div class="hpm-nodeset-tplx">
+    <label>SEO Description</label>
+    <div>
+      <textarea name="ext_seo_description" class="l4i-form-control" rows="3">{[=it.description]}</textarea>
+    </div>
+  </div>
+  <div class="hpm-nodeset-tplx">
+    <label>Canonical URL</label>
+    <div>
+      <input type="text" name="ext_seo_canonical" class="l4i-form-control" value="{[=it.canonical]}">
+    </div>
+  </div>
+  <div class="hpm-nodeset-tplx">
+    <label>Social Card Image</label>
+    <div>
+      <input type="text" name="ext_seo_image" class="l4i-form-control" value="{[=it.image]}">
+    </div>
+  </div>
+  <div class="hpm-nodeset-tplx">
+    <label>Search Engine Indexing</label>
+    <div>
+    <select class="form-control" name="ext_seo_noindex">
+      <option value="false" {[ if (!it.noindex) { ]}selected{[ } ]}>Allow</option>
+      <option value="true" {[ if (it.noindex) { ]}selected{[ } ]}>No Index</option>
+    </select>
+    </div>
+  </div>
+  <div class="hpm-nodeset-tplx">
+    <label>Search Engine Link Following</label>
+    <div>
+    <select class="form-control" name="ext_seo_nofollow">
+      <option value="false" {[ if (!it.nofollow) { ]}selected{[ } ]}>Allow</option>
+      <option value="true" {[ if (it.nofollow) { ]}selected{[ } ]}>No Follow</option>
+    </select>
+    </div>
+  </div>
+</script>
+
+
 <script id="hpm-nodeset-tplext_node_refer" type="text/html">
   <div class="hpm-nodeset-tplx">
     <label>Refer ID</label>
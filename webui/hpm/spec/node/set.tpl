@@ -185,6 +185,41 @@
             </select>
           </td>
         </tr>
+        <tr>
+          <td>SEO Metadata Per Entry</td>
+          <td>
+            <select class="form-control input-sm" name="ext_seo">
+            {[~it._general_onoff :gv]}
+            <option value="{[=gv.type]}" {[ if (it.extensions.seo == gv.type) { ]}selected{[ } ]}>{[=gv.name]}</option>
+            {[~]}
+            </select>
+          </td>
+        </tr>
+        <tr>
+          <td>Structured Data Type (schema.org)</td>
+          <td>
+            <select class="form-control input-sm" name="sd_type">
+            {[~it._structured_data_def :gv]}
+            <option value="{[=gv.type]}" {[ if (it.structured_data.type == gv.type) { ]}selected{[ } ]}>{[=gv.name]}</option>
+            {[~]}
+            </select>
+          </td>
+        </tr>
+        <tr>
+          <td>Structured Data Fields</td>
+          <td>
+            <input type="text" class="form-control input-sm" name="sd_headline" value="{[=it.structured_data.headline]}" placeholder="headline field, default: title">
+            <input type="text" class="form-control input-sm" name="sd_description" value="{[=it.structured_data.description]}" placeholder="description field">
+            <input type="text" class="form-control input-sm" name="sd_body" value="{[=it.structured_data.body]}" placeholder="article body field">
+          </td>
+        </tr>
+        <tr>
+          <td>Structured Data Terms</td>
+          <td>
+            <input type="text" class="form-control input-sm" name="sd_keywords" value="{[=it.structured_data.keywords]}" placeholder="keywords term">
+            <input type="text" class="form-control input-sm" name="sd_section" value="{[=it.structured_data.section]}" placeholder="section term">
+          </td>
+        </tr>
       </tbody>
       </table>
     </div>
@@ -44,6 +44,67 @@
     <table id="hpm-spec-route-params" width="100%"></table>
   </div>
   
+  <div class="form-group">
+    <label>Priority</label>
+    <input type="text" class="form-control" name="priority" 
+      placeholder="Higher priority routes are matched first" value="{[=it.priority || 0]}">
+  </div>
+
+  <div class="form-group">
+    <label>HTTP Cache</label>
+    <div class="row">
+      <div class="col-sm-6">
+        <select class="form-control" name="cache_scope">
+          <option value="" {[if (!it.cache || !it.cache.scope) { ]}selected{[ } ]}>Default (revalidate)</option>
+          <option value="public" {[if (it.cache && it.cache.scope == "public") { ]}selected{[ } ]}>Public</option>
+          <option value="private" {[if (it.cache && it.cache.scope == "private") { ]}selected{[ } ]}>Private</option>
+          <option value="no-store" {[if (it.cache && it.cache.scope == "no-store") { ]}selected{[ } ]}>No Store</option>
+        </select>
+      </div>
+      <div class="col-sm-6">
+        <input type="text" class="form-control" name="cache_max_age" 
+          placeholder="Max-Age in seconds" value="{[=(it.cache && it.cache.maxAge) || 0]}">
+      </div>
+    </div>
+  </div>
+
+  <div class="form-group">
+    <label>Feed</label>
+    <div class="row">
+      <div class="col-sm-6">
+        <select class="form-control" name="feed_format">
+          <option value="" {[if (!it.feed || !it.feed.format) { ]}selected{[ } ]}>None</option>
+          <option value="rss" {[if (it.feed && it.feed.format == "rss") { ]}selected{[ } ]}>RSS 2.0</option>
+          <option value="atom" {[if (it.feed && it.feed.format == "atom") { ]}selected{[ } ]}>Atom</option>
+        </select>
+      </div>
+      <div class="col-sm-6">
+        <select class="form-control" name="feed_content">
+          <option value="summary" {[if (!it.feed || it.feed.content != "full") { ]}selected{[ } ]}>Summary</option>
+          <option value="full" {[if (it.feed && it.feed.content == "full") { ]}selected{[ } ]}>Full Content</option>
+        </select>
+      </div>
+    </div>
+  </div>
+
+  <div class="form-group">
+    <label>Crawler Policy (X-Robots-Tag)</label>
+    <div class="row">
+      <div class="col-sm-6">
+        <select class="form-control" name="robots_noindex">
+          <option value="0" {[if (!it.robots || !it.robots.noindex) { ]}selected{[ } ]}>Index</option>
+          <option value="1" {[if (it.robots && it.robots.noindex) { ]}selected{[ } ]}>No Index</option>
+        </select>
+      </div>
+      <div class="col-sm-6">
+        <select class="form-control" name="robots_nofollow">
+          <option value="0" {[if (!it.robots || !it.robots.nofollow) { ]}selected{[ } ]}>Follow</option>
+          <option value="1" {[if (it.robots && it.robots.nofollow) { ]}selected{[ } ]}>No Follow</option>
+        </select>
+      </div>
+    </div>
+  </div>
+
   <div class="form-group">
     <label>Default</label>
     <select class="form-control" name="default">
@@ -59,7 +120,7 @@
     <input type="text" class="form-control input-sm" name="param_key" size="16" placeholder="Param Name" value="{[=it._key]}">
   </td>
   <td>
-    <input type="text" class="form-control input-sm" name="param_value" size="32" placeholder="Param Value" value="{[=it._value]}">
+    <input type="text" class="form-control input-sm" name="param_value" size="32" placeholder="Constraint: int, slug, enum:a|b, regex:..." value="{[=it._value]}">
   </td>
 </tr>
 </script>
@@ -3,6 +3,7 @@
   	<li class="active"><a class="l4i-nav-item" href="#sys/status">Status</a></li>
   	<li><a class="l4i-nav-item" href="#sys/iam-status">User Authentication</a></li>
   	<li><a class="l4i-nav-item" href="#sys/config">Settings</a></li>
+  	<li><a class="l4i-nav-item" href="#sys/site">Sites</a></li>
   </ul>
 </div>
 
@@ -0,0 +1,102 @@
+<div class="panel panel-default">
+  <div class="panel-heading">Sites</div>
+  <div class="panel-body">
+
+    <div id="hpm-sys-site-alert"></div>
+
+    {[? it.items && it.items.length > 0]}
+    <table width="100%" class="table table-striped">
+      <thead>
+        <tr>
+          <th>Name</th>
+          <th>Title</th>
+          <th>Hosts</th>
+          <th>Default Path</th>
+          <th>Updated</th>
+          <th></th>
+        </tr>
+      </thead>
+      <tbody>
+      {[~it.items :v]}
+      <tr>
+        <td>{[=v.name]}</td>
+        <td>{[=v.title]}</td>
+        <td>{[=v.hosts.join(", ")]}</td>
+        <td>{[=v.default_path]}</td>
+        <td>{[=l4i.UnixTimeFormat(v.updated, "Y-m-d H:i")]}</td>
+        <td align="right">
+          <button class="pure-button btapm-btn btapm-btn-small" onclick="hpSys.SiteEdit('{[=v.name]}')">Edit</button>
+          <button class="pure-button btapm-btn btapm-btn-small" onclick="hpSys.SiteDel('{[=v.name]}')">Delete</button>
+        </td>
+      </tr>
+      {[~]}
+      </tbody>
+    </table>
+    {[??]}
+    <p>No sites found, all hosts are served with the global settings.</p>
+    {[?]}
+  </div>
+</div>
+
+<div class="panel panel-default">
+  <div class="panel-heading">Site Settings</div>
+  <div id="hpm-sys-siteset" class="panel-body">
+
+    <div id="hpm-sys-siteset-alert"></div>
+
+    <table width="100%" class="table">
+      <tr>
+        <td width="20%">Name</td>
+        <td><input type="text" class="form-control" name="name" value=""></td>
+      </tr>
+      <tr>
+        <td>Title</td>
+        <td><input type="text" class="form-control" name="title" value=""></td>
+      </tr>
+      <tr>
+        <td>Hosts</td>
+        <td>
+          <input type="text" class="form-control" name="hosts" value="">
+          <p class="help-block">e.g. www.example.com, example.com</p>
+        </td>
+      </tr>
+      <tr>
+        <td>Default Path</td>
+        <td>
+          <input type="text" class="form-control" name="default_path" value="">
+          <p class="help-block">e.g. /blog</p>
+        </td>
+      </tr>
+      <tr>
+        <td>Modules</td>
+        <td>
+          <input type="text" class="form-control" name="modules" value="">
+          <p class="help-block">the service names of the modules, e.g. blog, gdoc. Empty for all modules</p>
+        </td>
+      </tr>
+      <tr>
+        <td>Languages</td>
+        <td>
+          <input type="text" class="form-control" name="languages" value="">
+          <p class="help-block">e.g. en,zh-cn</p>
+        </td>
+      </tr>
+      <tr>
+        <td>Theme Config</td>
+        <td>
+          <textarea class="form-control" name="theme_config" rows="4"></textarea>
+          <p class="help-block">toml, e.g. color_primary = "#ffc107"</p>
+        </td>
+      </tr>
+      <tr>
+        <td>Settings</td>
+        <td>
+          <textarea class="form-control" name="configs" rows="6"></textarea>
+          <p class="help-block">one setting per line, e.g. frontend_header_site_name = Example</p>
+        </td>
+      </tr>
+    </table>
+
+    <button class="pure-button btapm-btn btapm-btn-primary" onclick="hpSys.SiteSetCommit()">Save</button>
+  </div>
+</div>